@@ -0,0 +1,65 @@
+package api
+
+import (
+	"akinsho/gitgazer/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RequiredScopes are the OAuth scopes the application needs to read private repositories
+// and organisation membership
+var RequiredScopes = []string{"repo", "read:org"}
+
+// ErrUnauthorized is returned by every request once github rejects the access token
+// e.g. because it has been revoked
+var ErrUnauthorized = errors.New("the access token is invalid or has been revoked")
+
+// unauthorizedTransport converts 401 responses into ErrUnauthorized so that they can be
+// detected using errors.Is regardless of which request failed
+type unauthorizedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *unauthorizedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	return resp, nil
+}
+
+// Viewer returns the user the access token belongs to and the scopes it has been granted,
+// the REST API is used as the scopes are only reported in its response headers
+func (c *Client) Viewer() (*domain.Viewer, error) {
+	resp, err := c.httpClient().Get(c.apiURL() + "/user")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the current user: %s", resp.Status)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+	viewer := &domain.Viewer{Login: user.Login}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		viewer.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				viewer.Scopes = append(viewer.Scopes, scope)
+			}
+		}
+	}
+	return viewer, nil
+}
@@ -3,20 +3,75 @@ package api
 import (
 	"akinsho/gitgazer/domain"
 	"context"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/cli/oauth/api"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
 
+// DefaultHost is the host of github.com, other hosts are treated as github enterprise servers
+const DefaultHost = "github.com"
+
 type Client struct {
+	host string
+	// mu guards the clients which are replaced when the token changes whilst requests
+	// may be in flight on other goroutines
+	mu      sync.RWMutex
+	http    *http.Client
 	graphql *githubv4.Client
 }
 
-func Setup(token *api.AccessToken) (*Client, error) {
+// Setup creates a client for the github host, an empty host means github.com
+func Setup(host string, token *api.AccessToken) (*Client, error) {
+	if host == "" {
+		host = DefaultHost
+	}
+	client := &Client{host: host}
+	client.SetToken(token)
+	return client, nil
+}
+
+// apiURL returns the base URL of the host's REST API
+func (c *Client) apiURL() string {
+	if c.host == DefaultHost {
+		return "https://api.github.com"
+	}
+	return "https://" + c.host + "/api/v3"
+}
+
+// graphqlURL returns the URL of the host's GraphQL API
+func (c *Client) graphqlURL() string {
+	if c.host == DefaultHost {
+		return "https://api.github.com/graphql"
+	}
+	return "https://" + c.host + "/api/graphql"
+}
+
+// SetToken replaces the access token used by the client e.g. after the user logs in again
+func (c *Client) SetToken(token *api.AccessToken) {
 	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.Token})
 	httpClient := oauth2.NewClient(context.Background(), src)
-	return &Client{githubv4.NewClient(httpClient)}, nil
+	httpClient.Transport = &unauthorizedTransport{base: httpClient.Transport}
+	graphql := githubv4.NewEnterpriseClient(c.graphqlURL(), httpClient)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.http = httpClient
+	c.graphql = graphql
+}
+
+func (c *Client) httpClient() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.http
+}
+
+func (c *Client) graphqlClient() *githubv4.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.graphql
 }
 
 func (c *Client) ListStarredRepositories() ([]*domain.Repository, error) {
@@ -28,36 +83,15 @@ func (c *Client) ListStarredRepositories() ([]*domain.Repository, error) {
 		}
 	}
 
-	err := c.graphql.Query(
-		context.Background(),
-		&starredRepositoriesQuery,
-		map[string]interface{}{
-			"labelCount": githubv4.Int(20),
-			"issueCount": githubv4.Int(20),
-			"repoCount":  githubv4.Int(20),
-			"issuesOrderBy": githubv4.IssueOrder{
-				Direction: githubv4.OrderDirectionDesc,
-				Field:     githubv4.IssueOrderFieldUpdatedAt,
-			},
-			"prCount": githubv4.Int(5),
-			"prState": []githubv4.PullRequestState{githubv4.PullRequestStateOpen},
-			"pullRequestOrderBy": githubv4.IssueOrder{
-				Direction: githubv4.OrderDirectionDesc,
-				Field:     githubv4.IssueOrderFieldUpdatedAt,
-			},
-		},
-	)
+	variables := repositoryVariables()
+	variables["repoCount"] = githubv4.Int(20)
+	err := c.graphqlClient().Query(context.Background(), &starredRepositoriesQuery, variables)
 	return starredRepositoriesQuery.Viewer.StarredRepositories.Nodes, err
 }
 
-func (c *Client) FetchRepositoryByName(name, owner string) (*domain.Repository, error) {
-
-	var repositoryQuery struct {
-		Repository domain.Repository `graphql:"repository(name: $name, owner: $owner)"`
-	}
-	variables := map[string]interface{}{
-		"name":       githubv4.String(name),
-		"owner":      githubv4.String(owner),
+// repositoryVariables returns the variables required to query the fields of a domain.Repository
+func repositoryVariables() map[string]interface{} {
+	return map[string]interface{}{
 		"labelCount": githubv4.Int(20),
 		"issueCount": githubv4.Int(20),
 		"issuesOrderBy": githubv4.IssueOrder{
@@ -71,6 +105,110 @@ func (c *Client) FetchRepositoryByName(name, owner string) (*domain.Repository,
 			Field:     githubv4.IssueOrderFieldUpdatedAt,
 		},
 	}
-	err := c.graphql.Query(context.Background(), &repositoryQuery, variables)
+}
+
+func (c *Client) FetchRepositoryByName(name, owner string) (*domain.Repository, error) {
+
+	var repositoryQuery struct {
+		Repository domain.Repository `graphql:"repository(name: $name, owner: $owner)"`
+	}
+	variables := repositoryVariables()
+	variables["name"] = githubv4.String(name)
+	variables["owner"] = githubv4.String(owner)
+	err := c.graphqlClient().Query(context.Background(), &repositoryQuery, variables)
 	return &repositoryQuery.Repository, err
 }
+
+// SearchRepositories returns the repositories matching the query using github's search syntax
+func (c *Client) SearchRepositories(query string) ([]*domain.Repository, error) {
+	var searchQuery struct {
+		Search struct {
+			Nodes []struct {
+				Repository domain.Repository `graphql:"... on Repository"`
+			}
+		} `graphql:"search(query: $query, type: REPOSITORY, first: $repoCount)"`
+	}
+	variables := repositoryVariables()
+	variables["query"] = githubv4.String(query)
+	variables["repoCount"] = githubv4.Int(20)
+	if err := c.graphqlClient().Query(context.Background(), &searchQuery, variables); err != nil {
+		return nil, err
+	}
+	repos := []*domain.Repository{}
+	for _, node := range searchQuery.Search.Nodes {
+		repo := node.Repository
+		repos = append(repos, &repo)
+	}
+	return repos, nil
+}
+
+type workSearchNode struct {
+	Typename    string          `graphql:"__typename"`
+	PullRequest domain.WorkItem `graphql:"... on PullRequest"`
+	Issue       domain.WorkItem `graphql:"... on Issue"`
+}
+
+type workSearch struct {
+	Nodes []workSearchNode
+}
+
+func (s workSearch) items() []*domain.WorkItem {
+	items := []*domain.WorkItem{}
+	for _, node := range s.Nodes {
+		node := node
+		switch node.Typename {
+		case "PullRequest":
+			items = append(items, &node.PullRequest)
+		case "Issue":
+			items = append(items, &node.Issue)
+		}
+	}
+	return items
+}
+
+// ListViewerWork searches for open pull requests authored by, awaiting review from and issues
+// assigned to the current user across every repository they have access to.
+func (c *Client) ListViewerWork() (*domain.Work, error) {
+	var workQuery struct {
+		Authored        workSearch `graphql:"authored: search(query: $authoredQuery, type: ISSUE, first: $count)"`
+		ReviewRequested workSearch `graphql:"reviewRequested: search(query: $reviewQuery, type: ISSUE, first: $count)"`
+		Assigned        workSearch `graphql:"assigned: search(query: $assignedQuery, type: ISSUE, first: $count)"`
+	}
+	err := c.graphqlClient().Query(
+		context.Background(),
+		&workQuery,
+		map[string]interface{}{
+			"count":         githubv4.Int(50),
+			"authoredQuery": githubv4.String("is:open is:pr archived:false author:@me sort:updated-desc"),
+			"reviewQuery":   githubv4.String("is:open is:pr archived:false review-requested:@me sort:updated-desc"),
+			"assignedQuery": githubv4.String("is:open is:issue archived:false assignee:@me sort:updated-desc"),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Work{
+		Authored:        workQuery.Authored.items(),
+		ReviewRequested: workQuery.ReviewRequested.items(),
+		Assigned:        workQuery.Assigned.items(),
+	}, nil
+}
+
+// RateLimit returns the current state of the viewer's API rate limit
+func (c *Client) RateLimit() (*domain.RateLimit, error) {
+	var rateLimitQuery struct {
+		RateLimit struct {
+			Limit     int
+			Remaining int
+			ResetAt   time.Time
+		}
+	}
+	if err := c.graphqlClient().Query(context.Background(), &rateLimitQuery, nil); err != nil {
+		return nil, err
+	}
+	return &domain.RateLimit{
+		Limit:     rateLimitQuery.RateLimit.Limit,
+		Remaining: rateLimitQuery.RateLimit.Remaining,
+		ResetAt:   rateLimitQuery.RateLimit.ResetAt,
+	}, nil
+}
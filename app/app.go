@@ -14,11 +14,6 @@ type State struct {
 	Selected   *domain.Repository
 }
 
-type Logger interface {
-	Write(string)
-	Read() string
-}
-
 type Context struct {
 	Client *api.Client
 	DB     *storage.Database
@@ -27,12 +22,34 @@ type Context struct {
 	Logger Logger
 }
 
+// NewContext creates the context shared across the application with empty state
+func NewContext(config *Config, client *api.Client, db *storage.Database) *Context {
+	return &Context{
+		Client: client,
+		Config: config,
+		DB:     db,
+		State: &State{
+			Favourites: []*domain.Repository{},
+			Starred:    []*domain.Repository{},
+			Selected:   nil,
+		},
+	}
+}
+
+// WithConfig returns a copy of the context using the config, the client, database, state
+// and logger are shared with the original
+func (c *Context) WithConfig(config *Config) *Context {
+	copy := *c
+	copy.Config = config
+	return &copy
+}
+
 func (c *Context) SetLogger(log Logger) {
 	c.Logger = log
 }
 
 func (c *Context) GetStarred(index int) *domain.Repository {
-	if len(c.State.Starred) == 0 {
+	if index < 0 || index >= len(c.State.Starred) {
 		return nil
 	}
 	return c.State.Starred[index]
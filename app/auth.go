@@ -7,12 +7,17 @@ import (
 	"github.com/cli/oauth/api"
 )
 
-// getOAuthToken authenticate the user with Github and return an access token
-func getOAuthToken() (*api.AccessToken, error) {
+const (
+	clientIDEnv     = "OAUTH_CLIENT_ID"
+	clientSecretEnv = "OAUTH_CLIENT_SECRET"
+)
+
+// getOAuthToken authenticate the user with the Github host and return an access token
+func getOAuthToken(host string) (*api.AccessToken, error) {
 	flow := &oauth.Flow{
-		Host:         oauth.GitHubHost("https://github.com"),
-		ClientID:     os.Getenv("OAUTH_CLIENT_ID"),
-		ClientSecret: os.Getenv("OAUTH_CLIENT_SECRET"),
+		Host:         oauth.GitHubHost("https://" + host),
+		ClientID:     os.Getenv(clientIDEnv),
+		ClientSecret: os.Getenv(clientSecretEnv),
 		CallbackURI:  "http://127.0.0.1/callback",
 		Scopes:       []string{"repo", "read:org", "gist"},
 	}
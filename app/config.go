@@ -2,11 +2,16 @@ package app
 
 import (
 	"akinsho/gitgazer/domain"
-	"encoding/json"
+	"akinsho/gitgazer/keymap"
+	"akinsho/gitgazer/theme"
 	"errors"
-	"io/ioutil"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cli/oauth/api"
 	"gopkg.in/yaml.v2"
@@ -25,28 +30,164 @@ type Panels struct {
 	Log     LogOptions   `yaml:"log"`
 }
 
+// TabbedPaneOptions are the tabs shown in a pane in the order they appear and the
+// size of the pane relative to its neighbours
+type TabbedPaneOptions struct {
+	Panels []domain.PanelName `yaml:"panels"`
+	Size   int                `yaml:"size"`
+}
+
+type PaneOptions struct {
+	Show bool `yaml:"show"`
+	Size int  `yaml:"size"`
+}
+
+type SizeOptions struct {
+	Size int `yaml:"size"`
+}
+
+// LayoutOptions describe the panes of the TUI, the sidebar and main column are sized relative
+// to each other and the description, details and log panes within the main column
+type LayoutOptions struct {
+	Sidebar     TabbedPaneOptions `yaml:"sidebar"`
+	Main        SizeOptions       `yaml:"main"`
+	Description PaneOptions       `yaml:"description"`
+	Details     TabbedPaneOptions `yaml:"details"`
+	// Log is the size of the log pane which is shown when panels.log.enabled is true
+	Log SizeOptions `yaml:"log"`
+	// Help shows the key hints below the panes
+	Help bool `yaml:"help"`
+}
+
+type DaemonOptions struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+type CacheOptions struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type RefreshOptions struct {
+	// Interval between automatic refreshes of the TUI, zero disables them
+	Interval time.Duration `yaml:"interval"`
+}
+
+// TokenCommand are the shell commands used by the command token backend
+type TokenCommand struct {
+	Get   string `yaml:"get"`
+	Store string `yaml:"store"`
+	Erase string `yaml:"erase,omitempty"`
+	// NotFoundStatus is the exit status of get when no token is stored, defaults to 1
+	NotFoundStatus int `yaml:"not_found_status,omitempty"`
+}
+
+type TokenOptions struct {
+	// Backend used to store the access token: file, command or secret-service
+	Backend string       `yaml:"backend"`
+	Command TokenCommand `yaml:"command,omitempty"`
+}
+
+type AuthOptions struct {
+	// Sources of the access token in order of precedence
+	Sources []string `yaml:"sources"`
+}
+
+type ThemeOptions struct {
+	// Preset is the theme the colours are based on: dark, light or high-contrast
+	Preset string `yaml:"preset"`
+	// Colors overrides the colours of the preset e.g. `title: "#ff79c6"`
+	Colors map[string]string `yaml:"colors,omitempty"`
+	// Markdown is the glamour style used to render markdown, defaults to the preset's style
+	Markdown string `yaml:"markdown,omitempty"`
+}
+
+// Profile overrides the account used for a named profile e.g. a work account on a
+// github enterprise server, empty fields use the top level settings
+type Profile struct {
+	// Host of the github server, defaults to github.com
+	Host string `yaml:"host,omitempty"`
+	// Database is the file favourites are saved to, relative paths are inside the data directory
+	Database string       `yaml:"database,omitempty"`
+	Auth     AuthOptions  `yaml:"auth,omitempty"`
+	Token    TokenOptions `yaml:"token,omitempty"`
+}
+
 type UserConfig struct {
-	Panels Panels `yaml:"panels"`
+	// Profile is the name of the profile used when one is not passed with --profile
+	Profile  string             `yaml:"profile,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	Auth     AuthOptions        `yaml:"auth"`
+	Token    TokenOptions       `yaml:"token"`
+	Panels   Panels             `yaml:"panels"`
+	Layout   LayoutOptions      `yaml:"layout"`
+	Refresh  RefreshOptions     `yaml:"refresh"`
+	Daemon   DaemonOptions      `yaml:"daemon"`
+	Cache    CacheOptions       `yaml:"cache"`
+	Theme    ThemeOptions       `yaml:"theme"`
+	// Keys overrides the keys bound to an action e.g. `quit: ctrl-c`
+	Keys map[string]keymap.KeyList `yaml:"keys,omitempty"`
 }
 
 type Config struct {
+	home           string
 	directory      string
+	dataDirectory  string
+	cacheDirectory string
+	stateDirectory string
 	configFilepath string
 	tokenPath      string
 	StoragePath    string
-	Token          *api.AccessToken
-	UserConfig     *UserConfig
+	CachePath      string
+	// LogPath is the file the log is written to, it is shared by all profiles
+	LogPath string
+	// DaemonLogPath is the file the daemon writes its log to so that it does not rotate
+	// the log of the TUI whilst it is running
+	DaemonLogPath string
+	// Profile is the name of the active profile
+	Profile string
+	// Host is the github server of the active profile
+	Host    string
+	auth    AuthOptions
+	secrets SecretStore
+	Token   *api.AccessToken
+	// TokenSource is the source the access token was read from
+	TokenSource string
+	// Viewer is the user the access token belongs to, it is nil until the token is validated
+	Viewer     *domain.Viewer
+	Keymap     *keymap.Keymap
+	Theme      *theme.Theme
+	UserConfig *UserConfig
+}
+
+// ConfigOptions are the options used to locate the user's config
+type ConfigOptions struct {
+	// Path to the config file, if empty $GITGAZER_CONFIG or the default location is used
+	Path string
+	// Profile to use, if empty $GITGAZER_PROFILE or the profile set in the config file is used
+	Profile string
 }
 
 const (
-	configFile  = "config.yaml"
-	tokenFile   = "token.json"
-	configDir   = "gitgazer"
-	StoragePath = "gazers.db"
+	// DefaultProfile uses the top level settings of the config file
+	DefaultProfile = "default"
+	defaultHost    = "github.com"
+	configFile     = "config.yaml"
+	tokenFile      = "token.json"
+	appDir         = "gitgazer"
+	StoragePath    = "gazers.db"
+	CachePath      = "cache.db"
+	logFile        = "gitgazer.log"
+	daemonLogFile  = "gitgazer-daemon.log"
 )
 
 var defaults = &Config{
 	UserConfig: &UserConfig{
+		Auth: AuthOptions{
+			Sources: []string{EnvSource, StoredSource, GHSource, OAuthSource, PATSource},
+		},
+		Token: TokenOptions{
+			Backend: FileBackend,
+		},
 		Panels: Panels{
 			Log: LogOptions{
 				Enabled: false,
@@ -55,42 +196,240 @@ var defaults = &Config{
 				Preferred: domain.PullRequestPanel,
 			},
 		},
+		Layout: LayoutOptions{
+			Sidebar: TabbedPaneOptions{
+				Panels: []domain.PanelName{domain.StarredRepositoriesPanel, domain.FavouriteRepositoriesPanel},
+				Size:   1,
+			},
+			Main:        SizeOptions{Size: 3},
+			Description: PaneOptions{Show: true, Size: 1},
+			Details: TabbedPaneOptions{
+				Panels: []domain.PanelName{domain.IssuesPanel, domain.PullRequestPanel},
+				Size:   3,
+			},
+			Log:  SizeOptions{Size: 1},
+			Help: true,
+		},
+		Refresh: RefreshOptions{
+			Interval: 10 * time.Minute,
+		},
+		Daemon: DaemonOptions{
+			Interval: time.Hour,
+		},
+		Cache: CacheOptions{
+			TTL: 15 * time.Minute,
+		},
+		Theme: ThemeOptions{
+			Preset: theme.Dark,
+		},
 	},
 }
 
-// InitConfig setup the configuration file if need and read user options into state
-// create the access token if required or read it from where it is stored
-func InitConfig() (*Config, error) {
+// InitConfig setup the configuration file if need and read user options into state,
+// the access token is read separately using LoadToken
+func InitConfig(opts ConfigOptions) (*Config, error) {
+	config, err := NewConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Load(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewConfig locates the user's config and data files without reading them
+func NewConfig(opts ConfigOptions) (*Config, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
-	// TODO: investigate using https://github.com/adrg/xdg
-	// to correctly derive the correct config paths for the user's OS
-	dir := filepath.Join(home, ".config", configDir)
+	dir := xdgDir(home, configHomeEnv, ".config")
+	configPath := opts.Path
+	if configPath == "" {
+		configPath = os.Getenv(ConfigPathEnv)
+	}
+	if configPath == "" {
+		configPath = filepath.Join(dir, configFile)
+	}
+	profile := opts.Profile
+	if profile == "" {
+		profile = os.Getenv(ProfileEnv)
+	}
 	config := &Config{
+		home:           home,
 		directory:      dir,
-		configFilepath: filepath.Join(dir, configFile),
-		tokenPath:      filepath.Join(dir, tokenFile),
-		StoragePath:    filepath.Join(dir, StoragePath),
+		dataDirectory:  xdgDir(home, dataHomeEnv, ".local", "share"),
+		cacheDirectory: xdgDir(home, cacheHomeEnv, ".cache"),
+		stateDirectory: xdgDir(home, stateHomeEnv, ".local", "state"),
+		configFilepath: configPath,
+		Profile:        profile,
 	}
-	err = config.ensureDirectory()
+	config.LogPath = filepath.Join(config.stateDirectory, logFile)
+	config.DaemonLogPath = filepath.Join(config.stateDirectory, daemonLogFile)
+	config.setPaths(DefaultProfile, Profile{})
+	return config, nil
+}
+
+// Load creates the config file if it does not exist, otherwise it is read and validated
+// with any settings missing from it using their defaults. The profile to use is then selected.
+func (c *Config) Load() error {
+	err := c.ensureDirectory()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	err = c.migrate(filepath.Join(c.home, ".config", appDir))
+	if err != nil {
+		return err
 	}
-	if !config.exists() {
-		config.UserConfig, err = writeConfig(config.configFilepath, defaults.UserConfig)
+	if !c.exists() {
+		c.UserConfig, err = writeConfig(c.configFilepath, newUserConfig())
 	} else {
-		config.UserConfig, err = readConfig(config.configFilepath, defaults.UserConfig)
+		c.UserConfig, err = loadUserConfig(c.configFilepath)
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.buildSettings(); err != nil {
+		return err
+	}
+	profile := c.Profile
+	if profile == "" {
+		profile = c.UserConfig.Profile
 	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	return c.useProfile(profile)
+}
+
+// Reload returns a copy of the config with the user's settings read from the config file
+// again, the profile and token are kept so changes to them require a restart
+func (c *Config) Reload() (*Config, error) {
+	userConfig, err := loadUserConfig(c.configFilepath)
 	if err != nil {
 		return nil, err
 	}
-	err = config.retrieveAccessToken()
+	config := *c
+	config.UserConfig = userConfig
+	if err := config.buildSettings(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// buildSettings creates the keymap and theme from the user's settings
+func (c *Config) buildSettings() (err error) {
+	c.Keymap, err = keymap.New(c.UserConfig.Keys)
 	if err != nil {
+		return err
+	}
+	themeOptions := c.UserConfig.Theme
+	c.Theme, err = theme.New(themeOptions.Preset, themeOptions.Colors, themeOptions.Markdown)
+	return err
+}
+
+// Path returns the location of the config file
+func (c *Config) Path() string {
+	return c.configFilepath
+}
+
+// Profiles returns the names of the configured profiles including the default profile
+func (c *Config) Profiles() []string {
+	names := []string{DefaultProfile}
+	for name := range c.UserConfig.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ForProfile returns a copy of the config using the named profile, the token
+// still needs to be loaded for the new profile
+func (c *Config) ForProfile(name string) (*Config, error) {
+	config := *c
+	config.Token = nil
+	config.TokenSource = ""
+	config.Viewer = nil
+	if err := config.useProfile(name); err != nil {
 		return nil, err
 	}
-	return config, nil
+	return &config, nil
+}
+
+// useProfile points the config at the named profile's host, token and database
+func (c *Config) useProfile(name string) error {
+	profile, ok := c.UserConfig.Profiles[name]
+	if !ok && name != DefaultProfile {
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(c.Profiles(), ", "))
+	}
+	c.Profile = name
+	c.Host = profile.Host
+	if c.Host == "" {
+		c.Host = defaultHost
+	}
+	c.auth = c.UserConfig.Auth
+	if len(profile.Auth.Sources) > 0 {
+		c.auth = profile.Auth
+	}
+	token := c.UserConfig.Token
+	if profile.Token.Backend != "" {
+		token = profile.Token
+	}
+	c.setPaths(name, profile)
+	if err := ensureDirectory(filepath.Dir(c.StoragePath)); err != nil {
+		return err
+	}
+	secrets, err := newSecretStore(token, c.tokenPath, name)
+	if err != nil {
+		return err
+	}
+	c.secrets = secrets
+	return nil
+}
+
+// setPaths sets the location of the profile's token and databases, the default profile
+// uses the same files as versions without profiles
+func (c *Config) setPaths(name string, profile Profile) {
+	token, storage, cache := tokenFile, StoragePath, CachePath
+	if name != DefaultProfile {
+		token = fmt.Sprintf("token-%s.json", name)
+		storage = fmt.Sprintf("gazers-%s.db", name)
+		cache = fmt.Sprintf("cache-%s.db", name)
+	}
+	if profile.Database != "" {
+		storage = profile.Database
+	}
+	c.tokenPath = filepath.Join(c.directory, token)
+	c.StoragePath = storage
+	if !filepath.IsAbs(storage) {
+		c.StoragePath = filepath.Join(c.dataDirectory, storage)
+	}
+	c.CachePath = filepath.Join(c.cacheDirectory, cache)
+}
+
+// migrate moves files from the single directory used by previous versions
+// into their XDG base directories
+func (c *Config) migrate(legacyDir string) error {
+	moves := map[string]string{
+		filepath.Join(legacyDir, configFile):  filepath.Join(c.directory, configFile),
+		filepath.Join(legacyDir, tokenFile):   c.tokenPath,
+		filepath.Join(legacyDir, StoragePath): c.StoragePath,
+	}
+	for from, to := range moves {
+		if err := migrateFile(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newUserConfig returns a copy of the default user config
+func newUserConfig() *UserConfig {
+	config := *defaults.UserConfig
+	return &config
 }
 
 // writeConfig writes the default config file to the config directory
@@ -107,7 +446,8 @@ func writeConfig(path string, def *UserConfig) (*UserConfig, error) {
 	return def, nil
 }
 
-// readConfig returns a new decoded Config struct
+// readConfig returns a new decoded Config struct, fields that are not recognised or
+// have the wrong type are reported as a ConfigError along with their line numbers
 func readConfig(path string, config *UserConfig) (*UserConfig, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -116,17 +456,29 @@ func readConfig(path string, config *UserConfig) (*UserConfig, error) {
 	defer file.Close()
 
 	d := yaml.NewDecoder(file)
-	if err := d.Decode(&config); err != nil {
-		return nil, err
+	d.SetStrict(true)
+	if err := d.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		// the rest of the config is still decoded when some fields are invalid
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return config, newConfigError(path, err)
+		}
+		return nil, newConfigError(path, err)
 	}
 
 	return config, nil
 }
 
 func (c *Config) ensureDirectory() error {
-	if _, err := os.Stat(c.directory); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(c.directory, 0700)
-		if err != nil {
+	dirs := []string{
+		c.directory,
+		filepath.Dir(c.configFilepath),
+		c.dataDirectory,
+		c.cacheDirectory,
+		c.stateDirectory,
+	}
+	for _, dir := range dirs {
+		if err := ensureDirectory(dir); err != nil {
 			return err
 		}
 	}
@@ -134,56 +486,75 @@ func (c *Config) ensureDirectory() error {
 }
 
 func (c *Config) exists() bool {
-	if _, err := os.Stat(c.configFilepath); errors.Is(err, os.ErrNotExist) {
-		return false
-	}
-	return true
+	return fileExists(c.configFilepath)
 }
 
-func (c *Config) persistToken(token *api.AccessToken) (err error) {
-	jsonString, err := json.Marshal(token)
-	if err != nil {
+// LoadToken tries each of the configured token sources in order until one provides a
+// token. Interactive sources, which ask the user to log in, are skipped unless interactive
+// is true and the tokens they provide are saved to the secret store so they are read back
+// on the next start. ErrNotLoggedIn is returned if none of the sources has a token.
+func (c *Config) LoadToken(interactive bool) error {
+	sources := c.auth.Sources
+	if err := validateTokenSources(sources); err != nil {
 		return err
 	}
-	if err := ioutil.WriteFile(c.tokenPath, jsonString, 0666); err != nil {
-		return err
+	for _, name := range sources {
+		source := tokenSources[name]
+		if source.interactive && !interactive {
+			continue
+		}
+		if err := c.loadTokenFrom(name, source); !errors.Is(err, errSourceUnavailable) {
+			return err
+		}
 	}
-	return nil
+	return ErrNotLoggedIn
 }
 
-// readToken reads the token from the file and unmarshals it into a token struct
-func (c *Config) readToken() (*api.AccessToken, error) {
-	var token api.AccessToken
-	jsonString, err := ioutil.ReadFile(c.tokenPath)
-	if err != nil {
-		return nil, err
+// Login asks the user to log in again using the first available interactive token source
+// replacing the current token e.g. because it has been revoked
+func (c *Config) Login() error {
+	for _, name := range c.auth.Sources {
+		source, ok := tokenSources[name]
+		if !ok || !source.interactive {
+			continue
+		}
+		if err := c.loadTokenFrom(name, source); !errors.Is(err, errSourceUnavailable) {
+			return err
+		}
 	}
-	if err := json.Unmarshal(jsonString, &token); err != nil {
-		return nil, err
+	return fmt.Errorf(
+		"unable to log in, add %s or %s to auth.sources and run gitgazer from a terminal or set $%s",
+		OAuthSource,
+		PATSource,
+		clientIDEnv,
+	)
+}
+
+// Logout deletes the token saved in the secret store
+func (c *Config) Logout() error {
+	if err := c.secrets.Delete(); err != nil {
+		return err
+	}
+	if c.TokenSource == StoredSource || tokenSources[c.TokenSource].interactive {
+		c.Token = nil
+		c.TokenSource = ""
 	}
-	return &token, nil
+	return nil
 }
 
-// retrieveAccessToken if an access token has been saved previously then read it back
-// into memory from the file where it is saved otherwise start a new oauth flow and persist
-// the token to the file
-func (c *Config) retrieveAccessToken() error {
-	var token *api.AccessToken
-	if _, err := os.Stat(c.tokenPath); errors.Is(err, os.ErrNotExist) {
-		token, err = getOAuthToken()
-		if err != nil {
-			return err
-		}
-		err = c.persistToken(token)
-		if err != nil {
-			return err
-		}
-	} else {
-		token, err = c.readToken()
-		if err != nil {
+func (c *Config) loadTokenFrom(name string, source tokenSource) error {
+	token, err := source.get(c)
+	if errors.Is(err, errSourceUnavailable) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to read token from %s: %w", name, err)
+	}
+	if source.interactive {
+		if err := c.secrets.Set(token); err != nil {
 			return err
 		}
 	}
 	c.Token = token
+	c.TokenSource = name
 	return nil
 }
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel is the severity of a log message, messages below the logger's level are dropped
+type LogLevel int
+
+const (
+	DebugLevel LogLevel = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+const (
+	// maxLogSize is the size the log file can grow to before it is rotated
+	maxLogSize = 1024 * 1024
+	// maxLogBackups is the number of rotated log files which are kept e.g. gitgazer.log.1
+	maxLogBackups = 3
+	// maxLogHistory is the number of recent entries kept in memory
+	maxLogHistory = 200
+)
+
+var levelNames = map[LogLevel]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseLogLevel returns the level matching the name e.g. "debug"
+func ParseLogLevel(name string) (LogLevel, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(n, strings.TrimSpace(name)) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+// LogEntry is a single message written to the log
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+}
+
+func (e LogEntry) String() string {
+	return fmt.Sprintf(
+		"%s %-5s %s",
+		e.Time.Format(time.RFC3339),
+		strings.ToUpper(e.Level.String()),
+		e.Message,
+	)
+}
+
+// LogSink receives the entries written to a Logger e.g. the log panel of the TUI
+type LogSink interface {
+	Write(entry LogEntry)
+}
+
+type Logger interface {
+	Log(level LogLevel, message string)
+	Debug(message string)
+	Info(message string)
+	Warn(message string)
+	Error(message string)
+	// Recent returns the most recent entries from oldest to newest
+	Recent() []LogEntry
+	// SetSink sends all new entries to the sink as well as the recent entries,
+	// a nil sink stops sending them
+	SetSink(sink LogSink)
+}
+
+// FileLogger writes log entries at or above its level to a file which is rotated once it
+// grows too large. Entries are also sent to the sink if one is set.
+type FileLogger struct {
+	mu      sync.Mutex
+	level   LogLevel
+	path    string
+	file    *os.File
+	size    int64
+	sink    LogSink
+	history []LogEntry
+}
+
+// NewLogger creates a logger which only keeps entries in memory until a file is opened
+func NewLogger(level LogLevel) *FileLogger {
+	return &FileLogger{level: level}
+}
+
+// OpenFile appends all further entries to the file at the path
+func (l *FileLogger) OpenFile(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := ensureDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+	l.path = path
+	return l.open()
+}
+
+func (l *FileLogger) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotate renames the log file to gitgazer.log.1 moving older backups along and
+// deleting the oldest, then a new file is opened. If another process e.g. a command
+// run whilst the TUI is open has already rotated the file the new file is opened instead.
+//
+// If rotating fails the file at the path is opened again so logging continues, another
+// attempt is made once a further maxLogSize has been written.
+func (l *FileLogger) rotate() error {
+	err := l.moveFiles()
+	if l.file != nil {
+		l.size = 0
+		return err
+	}
+	if openErr := l.open(); openErr != nil && err == nil {
+		err = openErr
+	} else if err != nil {
+		l.size = 0
+	}
+	return err
+}
+
+// moveFiles closes the log file and moves it and its backups along unless another
+// process has already done so
+func (l *FileLogger) moveFiles() error {
+	current, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+	if info, err := os.Stat(l.path); err != nil || !os.SameFile(current, info) {
+		return nil
+	}
+	for i := maxLogBackups - 1; i > 0; i-- {
+		from := fmt.Sprintf("%s.%d", l.path, i)
+		if fileExists(from) {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", l.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(l.path, l.path+".1")
+}
+
+// Close closes the log file
+func (l *FileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Log writes the message at the level
+func (l *FileLogger) Log(level LogLevel, message string) {
+	if level < l.level {
+		return
+	}
+	entry := LogEntry{Time: time.Now(), Level: level, Message: message}
+	entries := []LogEntry{entry}
+	l.mu.Lock()
+	if l.file != nil {
+		if l.size >= maxLogSize {
+			// the TUI owns the terminal so the failure is only kept with the other entries
+			if err := l.rotate(); err != nil {
+				entries = append(entries, LogEntry{
+					Time:    entry.Time,
+					Level:   ErrorLevel,
+					Message: fmt.Sprintf("failed to rotate the log: %s", err),
+				})
+			}
+		}
+		if l.file != nil {
+			for _, e := range entries {
+				n, _ := fmt.Fprintln(l.file, e)
+				l.size += int64(n)
+			}
+		}
+	}
+	l.history = append(l.history, entries...)
+	if len(l.history) > maxLogHistory {
+		l.history = l.history[len(l.history)-maxLogHistory:]
+	}
+	sink := l.sink
+	l.mu.Unlock()
+	if sink != nil {
+		for _, e := range entries {
+			sink.Write(e)
+		}
+	}
+}
+
+func (l *FileLogger) Debug(message string) { l.Log(DebugLevel, message) }
+func (l *FileLogger) Info(message string)  { l.Log(InfoLevel, message) }
+func (l *FileLogger) Warn(message string)  { l.Log(WarnLevel, message) }
+func (l *FileLogger) Error(message string) { l.Log(ErrorLevel, message) }
+
+func (l *FileLogger) Recent() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry{}, l.history...)
+}
+
+func (l *FileLogger) SetSink(sink LogSink) {
+	l.mu.Lock()
+	l.sink = sink
+	history := append([]LogEntry{}, l.history...)
+	l.mu.Unlock()
+	if sink == nil {
+		return
+	}
+	for _, entry := range history {
+		sink.Write(entry)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import "testing"
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected LogLevel
+		err      bool
+	}{
+		{name: "debug", expected: DebugLevel},
+		{name: "info", expected: InfoLevel},
+		{name: "warn", expected: WarnLevel},
+		{name: "error", expected: ErrorLevel},
+		{name: "WARN", expected: WarnLevel},
+		{name: " Error ", expected: ErrorLevel},
+		{name: "warning", err: true},
+		{name: "", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, err := ParseLogLevel(test.name)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", levelNames[level])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if level != test.expected {
+				t.Errorf("expected %s, got %s", levelNames[test.expected], levelNames[level])
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	configHomeEnv = "XDG_CONFIG_HOME"
+	dataHomeEnv   = "XDG_DATA_HOME"
+	cacheHomeEnv  = "XDG_CACHE_HOME"
+	stateHomeEnv  = "XDG_STATE_HOME"
+	// ConfigPathEnv overrides the path of the config file
+	ConfigPathEnv = "GITGAZER_CONFIG"
+	// ProfileEnv selects the profile to use
+	ProfileEnv = "GITGAZER_PROFILE"
+)
+
+// xdgDir returns the application's directory inside the base directory named by the
+// environment variable, if it is unset or relative the fallback inside the user's
+// home directory is used instead as required by the XDG base directory specification
+func xdgDir(home, env string, fallback ...string) string {
+	base := os.Getenv(env)
+	if base == "" || !filepath.IsAbs(base) {
+		base = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return filepath.Join(base, appDir)
+}
+
+// ensureDirectory creates the directory if it does not already exist
+func ensureDirectory(dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	return true
+}
+
+// migrateFile moves a file from the location used by previous versions to its new location
+// unless a file already exists there
+func migrateFile(from, to string) error {
+	if from == to || !fileExists(from) || fileExists(to) {
+		return nil
+	}
+	if err := ensureDirectory(filepath.Dir(to)); err != nil {
+		return err
+	}
+	// renaming fails if the directories are on different devices so fallback to copying
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	if err := copyFile(from, to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+func copyFile(from, to string) error {
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
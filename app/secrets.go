@@ -0,0 +1,222 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/cli/oauth/api"
+)
+
+const (
+	FileBackend          = "file"
+	CommandBackend       = "command"
+	SecretServiceBackend = "secret-service"
+)
+
+// AccountEnv is set to the name of the profile when running the commands of the command
+// backend so that each profile can store its token separately
+const AccountEnv = "GITGAZER_ACCOUNT"
+
+// tokenFileMode only allows the owner to read or write the token
+const tokenFileMode = 0600
+
+var ErrSecretNotFound = errors.New("no access token has been stored")
+
+// defaultNotFoundStatus is the exit status of the get command when there is no token
+// e.g. of pass show and secret-tool lookup
+const defaultNotFoundStatus = 1
+
+// SecretStore persists the user's access token
+type SecretStore interface {
+	// Get returns the stored token or ErrSecretNotFound if there isn't one
+	Get() (*api.AccessToken, error)
+	Set(token *api.AccessToken) error
+	Delete() error
+}
+
+// newSecretStore creates the store for the backend chosen in the user's config
+func newSecretStore(opts TokenOptions, tokenPath string, account string) (SecretStore, error) {
+	switch opts.Backend {
+	case "", FileBackend:
+		return &fileStore{path: tokenPath}, nil
+	case CommandBackend:
+		if opts.Command.Get == "" || opts.Command.Store == "" {
+			return nil, fmt.Errorf("the %s token backend requires token.command.get and token.command.store", CommandBackend)
+		}
+		notFound := opts.Command.NotFoundStatus
+		if notFound == 0 {
+			notFound = defaultNotFoundStatus
+		}
+		if notFound < 0 || notFound > 255 {
+			return nil, fmt.Errorf("token.command.not_found_status must be between 1 and 255, got %d", notFound)
+		}
+		var erase []string
+		if opts.Command.Erase != "" {
+			erase = []string{"sh", "-c", opts.Command.Erase}
+		}
+		return &commandStore{
+			get:      []string{"sh", "-c", opts.Command.Get},
+			store:    []string{"sh", "-c", opts.Command.Store},
+			erase:    erase,
+			env:      []string{AccountEnv + "=" + account},
+			notFound: notFound,
+		}, nil
+	case SecretServiceBackend:
+		attributes := []string{"service", appDir, "account", account}
+		return &commandStore{
+			get:      append([]string{"secret-tool", "lookup"}, attributes...),
+			store:    append([]string{"secret-tool", "store", "--label=gitgazer access token"}, attributes...),
+			erase:    append([]string{"secret-tool", "clear"}, attributes...),
+			notFound: defaultNotFoundStatus,
+		}, nil
+	}
+	return nil, fmt.Errorf(
+		"unknown token backend %q, expected one of %s, %s or %s",
+		opts.Backend,
+		FileBackend,
+		CommandBackend,
+		SecretServiceBackend,
+	)
+}
+
+// fileStore saves the token as JSON in a file only readable by the user
+type fileStore struct {
+	path string
+}
+
+func (f *fileStore) Get() (*api.AccessToken, error) {
+	info, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSecretNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if err := f.checkPermissions(info); err != nil {
+		return nil, err
+	}
+	contents, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseToken(contents)
+}
+
+// checkPermissions ensures the token is not readable by other users, previous versions
+// created it with 0666 so it is tightened rather than refused with a warning
+func (f *fileStore) checkPermissions(info os.FileInfo) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	mode := info.Mode().Perm()
+	if mode&0077 == 0 {
+		return nil
+	}
+	fmt.Fprintf(
+		os.Stderr,
+		"warning: %s was accessible by other users (%#o), restricting it to %#o\n",
+		f.path,
+		mode,
+		tokenFileMode,
+	)
+	return os.Chmod(f.path, tokenFileMode)
+}
+
+func (f *fileStore) Set(token *api.AccessToken) error {
+	contents, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(f.path, contents, tokenFileMode); err != nil {
+		return err
+	}
+	// WriteFile does not change the permissions of an existing file
+	return os.Chmod(f.path, tokenFileMode)
+}
+
+func (f *fileStore) Delete() error {
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// commandStore delegates to external programs e.g. pass or secret-tool, the token is
+// written to the store command's stdin and read from the get command's stdout
+type commandStore struct {
+	get   []string
+	store []string
+	erase []string
+	// env is added to the environment of the commands
+	env []string
+	// notFound is the exit status of get meaning no token is stored, any other failure
+	// e.g. the command not existing or a locked keyring is reported as an error
+	notFound int
+}
+
+func (c *commandStore) command(args []string) *exec.Cmd {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), c.env...)
+	return cmd
+}
+
+func (c *commandStore) Get() (*api.AccessToken, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := c.command(c.get)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == c.notFound && strings.TrimSpace(stdout.String()) == "" {
+			return nil, ErrSecretNotFound
+		}
+		return nil, fmt.Errorf("failed to read token using %s: %w %s", c.get[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if strings.TrimSpace(stdout.String()) == "" {
+		return nil, ErrSecretNotFound
+	}
+	return parseToken(stdout.Bytes())
+}
+
+func (c *commandStore) Set(token *api.AccessToken) error {
+	var stderr bytes.Buffer
+	cmd := c.command(c.store)
+	cmd.Stdin = strings.NewReader(token.Token + "\n")
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store token using %s: %w %s", c.store[0], err, stderr.String())
+	}
+	return nil
+}
+
+func (c *commandStore) Delete() error {
+	if len(c.erase) == 0 {
+		return nil
+	}
+	var stderr bytes.Buffer
+	cmd := c.command(c.erase)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to delete token using %s: %w %s", c.erase[0], err, stderr.String())
+	}
+	return nil
+}
+
+// parseToken reads a token saved either as JSON or as the raw token string
+func parseToken(contents []byte) (*api.AccessToken, error) {
+	contents = bytes.TrimSpace(contents)
+	if bytes.HasPrefix(contents, []byte("{")) {
+		var token api.AccessToken
+		if err := json.Unmarshal(contents, &token); err != nil {
+			return nil, err
+		}
+		return &token, nil
+	}
+	return &api.AccessToken{Token: string(contents)}, nil
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"akinsho/gitgazer/api"
+	"akinsho/gitgazer/storage"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Open authenticates the config's profile and opens its database returning the context
+// used by the rest of the application
+func Open(config *Config) (*Context, error) {
+	client, err := Authenticate(config)
+	if err != nil {
+		return nil, err
+	}
+	db, err := storage.Setup(config.StoragePath, config.CachePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewContext(config, client, db), nil
+}
+
+// Authenticate loads the access token and creates the API client. The token is validated by
+// fetching the current user, if github rejects it the user is asked to log in again. Other
+// failures are only reported as a warning so that cached data can still be used offline.
+func Authenticate(config *Config) (*api.Client, error) {
+	if err := config.LoadToken(true); err != nil {
+		return nil, err
+	}
+	client, err := api.Setup(config.Host, config.Token)
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := client.Viewer()
+	if errors.Is(err, api.ErrUnauthorized) {
+		fmt.Fprintf(os.Stderr, "The token from %s was rejected by github, please log in again\n", config.TokenSource)
+		if err := config.Login(); err != nil {
+			return nil, err
+		}
+		client.SetToken(config.Token)
+		viewer, err = client.Viewer()
+	}
+	if errors.Is(err, api.ErrUnauthorized) {
+		return nil, err
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to validate the access token: %s\n", err)
+		return client, nil
+	}
+	config.Viewer = viewer
+	if missing := viewer.MissingScopes(api.RequiredScopes); len(missing) > 0 {
+		fmt.Fprintf(
+			os.Stderr,
+			"warning: the access token is missing the %s scopes, some repositories may not be shown\n",
+			strings.Join(missing, ", "),
+		)
+	}
+	return client, nil
+}
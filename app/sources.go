@@ -0,0 +1,167 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/oauth/api"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvSource reads the token from $GITHUB_TOKEN or $GH_TOKEN, or for enterprise hosts
+	// $GH_ENTERPRISE_TOKEN or $GITHUB_ENTERPRISE_TOKEN
+	EnvSource = "env"
+	// StoredSource reads the token previously saved to the secret store
+	StoredSource = "stored"
+	// GHSource reads the token used by the gh CLI
+	GHSource = "gh"
+	// OAuthSource authenticates using the OAuth device flow
+	OAuthSource = "oauth"
+	// PATSource prompts for a personal access token
+	PATSource = "pat"
+)
+
+var (
+	tokenEnvVars           = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	enterpriseTokenEnvVars = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+)
+
+// ErrNotLoggedIn is returned when none of the token sources can provide a token
+var ErrNotLoggedIn = fmt.Errorf(
+	"no access token found, set $GITHUB_TOKEN, log in with the gh CLI or set $%s for the OAuth flow",
+	clientIDEnv,
+)
+
+// errSourceUnavailable is returned by a token source which cannot provide a token
+// so that the next source is tried
+var errSourceUnavailable = errors.New("token source is unavailable")
+
+type tokenSource struct {
+	// interactive sources ask the user for a new token which is then saved to the secret store
+	interactive bool
+	get         func(c *Config) (*api.AccessToken, error)
+}
+
+var tokenSources = map[string]tokenSource{
+	EnvSource:    {get: envToken},
+	StoredSource: {get: storedToken},
+	GHSource:     {get: ghToken},
+	OAuthSource:  {interactive: true, get: oauthToken},
+	PATSource:    {interactive: true, get: patToken},
+}
+
+// validateTokenSources checks every source in the list is known
+func validateTokenSources(sources []string) error {
+	if len(sources) == 0 {
+		return errors.New("auth.sources must contain at least one token source")
+	}
+	for _, source := range sources {
+		if _, ok := tokenSources[source]; !ok {
+			return fmt.Errorf(
+				"unknown token source %q, expected one of %s, %s, %s, %s or %s",
+				source,
+				EnvSource,
+				StoredSource,
+				GHSource,
+				OAuthSource,
+				PATSource,
+			)
+		}
+	}
+	return nil
+}
+
+func envToken(c *Config) (*api.AccessToken, error) {
+	names := tokenEnvVars
+	if c.Host != defaultHost {
+		names = enterpriseTokenEnvVars
+	}
+	for _, name := range names {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return &api.AccessToken{Token: token}, nil
+		}
+	}
+	return nil, errSourceUnavailable
+}
+
+func storedToken(c *Config) (*api.AccessToken, error) {
+	token, err := c.secrets.Get()
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, errSourceUnavailable
+	}
+	return token, err
+}
+
+// ghHosts is the subset of the gh CLI's hosts.yml needed to read its token
+type ghHosts map[string]struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+// ghToken reads the token from the gh CLI's hosts.yml, newer versions of gh keep
+// the token in the system keyring so `gh auth token` is used if it is missing
+func ghToken(c *Config) (*api.AccessToken, error) {
+	dir := os.Getenv("GH_CONFIG_DIR")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		base := os.Getenv(configHomeEnv)
+		if base == "" || !filepath.IsAbs(base) {
+			base = filepath.Join(home, ".config")
+		}
+		dir = filepath.Join(base, "gh")
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "hosts.yml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errSourceUnavailable
+	} else if err != nil {
+		return nil, err
+	}
+	hosts := ghHosts{}
+	if err := yaml.Unmarshal(contents, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to read the gh CLI's hosts.yml: %w", err)
+	}
+	if host, ok := hosts[c.Host]; ok && host.OAuthToken != "" {
+		return &api.AccessToken{Token: host.OAuthToken}, nil
+	}
+	output, err := exec.Command("gh", "auth", "token", "--hostname", c.Host).Output()
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return nil, errSourceUnavailable
+	}
+	return &api.AccessToken{Token: strings.TrimSpace(string(output))}, nil
+}
+
+func oauthToken(c *Config) (*api.AccessToken, error) {
+	if os.Getenv(clientIDEnv) == "" {
+		return nil, errSourceUnavailable
+	}
+	return getOAuthToken(c.Host)
+}
+
+// patToken prompts the user to paste a personal access token without echoing it
+func patToken(c *Config) (*api.AccessToken, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errSourceUnavailable
+	}
+	fmt.Fprintf(os.Stderr, "Create a personal access token at https://%s/settings/tokens\n", c.Host)
+	fmt.Fprint(os.Stderr, "Paste your token: ")
+	contents, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return nil, errSourceUnavailable
+	}
+	return &api.AccessToken{Token: token}, nil
+}
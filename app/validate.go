@@ -0,0 +1,280 @@
+package app
+
+import (
+	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/keymap"
+	"akinsho/gitgazer/theme"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigError lists every problem found in the user's config file
+type ConfigError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s:\n  %s", e.Path, strings.Join(e.Problems, "\n  "))
+}
+
+// newConfigError converts an error decoding the config into a ConfigError, yaml reports
+// every unknown field or wrong type at once prefixed with its line number
+func newConfigError(path string, err error) *ConfigError {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return &ConfigError{Path: path, Problems: typeErr.Errors}
+	}
+	return &ConfigError{Path: path, Problems: []string{strings.TrimPrefix(err.Error(), "yaml: ")}}
+}
+
+// CheckConfig reads and validates the config file without changing it, every problem
+// found is returned as a ConfigError along with the keys missing from the file
+func CheckConfig(path string) (missing []string, err error) {
+	if !fileExists(path) {
+		return nil, fmt.Errorf("%s does not exist, it is created when gitgazer starts", path)
+	}
+	if _, err := loadUserConfig(path); err != nil {
+		return nil, err
+	}
+	return mergeMissingKeys(path, false)
+}
+
+// AddMissingKeys writes the settings missing from the config file using their default
+// values keeping the previous file as a backup, the names of the added keys are returned
+func AddMissingKeys(path string) ([]string, error) {
+	return mergeMissingKeys(path, true)
+}
+
+// loadUserConfig reads and validates the config file, problems decoding the file and
+// invalid values are reported together
+func loadUserConfig(path string) (*UserConfig, error) {
+	config, err := readConfig(path, newUserConfig())
+	problems := []string{}
+	var configErr *ConfigError
+	if errors.As(err, &configErr) && config != nil {
+		problems = append(problems, configErr.Problems...)
+	} else if err != nil {
+		return nil, err
+	}
+	problems = append(problems, validate(config)...)
+	if len(problems) > 0 {
+		return nil, &ConfigError{Path: path, Problems: problems}
+	}
+	return config, nil
+}
+
+// validate checks the values of the config which yaml cannot e.g. that durations are not
+// negative and the names of token sources, returning a description of each problem
+func validate(config *UserConfig) []string {
+	problems := []string{}
+	add := func(key string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, err))
+		}
+	}
+	add("auth.sources", validateTokenSources(config.Auth.Sources))
+	_, err := newSecretStore(config.Token, "", "")
+	add("token", err)
+	for name, profile := range config.Profiles {
+		key := "profiles." + name
+		if strings.Contains(profile.Host, "/") {
+			add(key+".host", fmt.Errorf("expected a host name e.g. github.example.com, got %q", profile.Host))
+		}
+		if len(profile.Auth.Sources) > 0 {
+			add(key+".auth.sources", validateTokenSources(profile.Auth.Sources))
+		}
+		if profile.Token.Backend != "" {
+			_, err := newSecretStore(profile.Token, "", "")
+			add(key+".token", err)
+		}
+	}
+	add("token.command", validateSharedTokenCommands(config))
+	if _, ok := config.Profiles[config.Profile]; config.Profile != "" && config.Profile != DefaultProfile && !ok {
+		add("profile", fmt.Errorf("unknown profile %q", config.Profile))
+	}
+	switch config.Panels.Details.Preferred {
+	case domain.PullRequestPanel, domain.IssuesPanel:
+	default:
+		add("panels.details.preferred", fmt.Errorf(
+			"expected %s or %s, got %s",
+			domain.PullRequestPanel,
+			domain.IssuesPanel,
+			config.Panels.Details.Preferred,
+		))
+	}
+	layout := config.Layout
+	add("layout.sidebar.panels", validatePanels(
+		layout.Sidebar.Panels,
+		domain.StarredRepositoriesPanel,
+		domain.FavouriteRepositoriesPanel,
+	))
+	add("layout.details.panels", validatePanels(
+		layout.Details.Panels,
+		domain.IssuesPanel,
+		domain.PullRequestPanel,
+	))
+	sizes := map[string]int{
+		"layout.sidebar.size":     layout.Sidebar.Size,
+		"layout.main.size":        layout.Main.Size,
+		"layout.description.size": layout.Description.Size,
+		"layout.details.size":     layout.Details.Size,
+		"layout.log.size":         layout.Log.Size,
+	}
+	for key, size := range sizes {
+		if size <= 0 {
+			add(key, fmt.Errorf("must be greater than zero, got %d", size))
+		}
+	}
+	if config.Refresh.Interval < 0 {
+		add("refresh.interval", errors.New("must not be negative, use 0 to disable refreshing"))
+	}
+	if config.Daemon.Interval <= 0 {
+		add("daemon.interval", errors.New("must be greater than zero"))
+	}
+	if config.Cache.TTL < 0 {
+		add("cache.ttl", errors.New("must not be negative"))
+	}
+	if _, err := keymap.New(config.Keys); err != nil {
+		problems = append(problems, err.Error())
+	}
+	themeOptions := config.Theme
+	if _, err := theme.New(themeOptions.Preset, themeOptions.Colors, themeOptions.Markdown); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// validateSharedTokenCommands returns an error if profiles would store their tokens using
+// the same commands, overwriting each other's token, because the commands do not use the
+// profile name in $GITGAZER_ACCOUNT
+func validateSharedTokenCommands(config *UserConfig) error {
+	shared := map[string][]string{}
+	check := func(name string, opts TokenOptions) {
+		if opts.Backend != CommandBackend || strings.Contains(opts.Command.Store, AccountEnv) {
+			return
+		}
+		shared[opts.Command.Store] = append(shared[opts.Command.Store], name)
+	}
+	check(DefaultProfile, config.Token)
+	for name, profile := range config.Profiles {
+		if profile.Token.Backend != "" {
+			check(name, profile.Token)
+		} else {
+			check(name, config.Token)
+		}
+	}
+	for _, names := range shared {
+		if len(names) > 1 {
+			sort.Strings(names)
+			return fmt.Errorf(
+				"the profiles %s share the same store command, use $%s in it to keep their tokens separate",
+				strings.Join(names, ", "),
+				AccountEnv,
+			)
+		}
+	}
+	return nil
+}
+
+// validatePanels checks that at least one panel is shown, each panel is shown once and
+// that only the allowed panels are used
+func validatePanels(panels []domain.PanelName, allowed ...domain.PanelName) error {
+	if len(panels) == 0 {
+		return errors.New("at least one panel must be shown")
+	}
+	names := []string{}
+	for _, panel := range allowed {
+		names = append(names, panel.String())
+	}
+	seen := map[domain.PanelName]bool{}
+	for _, panel := range panels {
+		found := false
+		for _, a := range allowed {
+			found = found || a == panel
+		}
+		if !found {
+			return fmt.Errorf("%s cannot be shown here, expected %s", panel, strings.Join(names, " or "))
+		}
+		if seen[panel] {
+			return fmt.Errorf("%s is shown more than once", panel)
+		}
+		seen[panel] = true
+	}
+	return nil
+}
+
+// mergeMissingKeys adds settings missing from the config file e.g. those added by newer versions
+// using their default values, the user's values are left untouched. If write is false the file
+// is not changed. The dotted names of the missing keys are returned.
+func mergeMissingKeys(path string, write bool) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	user := yaml.MapSlice{}
+	if err := yaml.Unmarshal(contents, &user); err != nil && !errors.Is(err, io.EOF) {
+		return nil, newConfigError(path, err)
+	}
+	encoded, err := yaml.Marshal(defaults.UserConfig)
+	if err != nil {
+		return nil, err
+	}
+	def := yaml.MapSlice{}
+	if err := yaml.Unmarshal(encoded, &def); err != nil {
+		return nil, err
+	}
+	merged, missing := mergeMapSlices(user, def, "")
+	if len(missing) == 0 || !write {
+		return missing, nil
+	}
+	output, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	// rewriting the file loses any comments so the original is kept as a backup
+	if err := ioutil.WriteFile(path+".bak", contents, 0600); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return missing, ioutil.WriteFile(path, output, info.Mode().Perm())
+}
+
+// mergeMapSlices appends the keys of def that are missing from user recursing into nested
+// mappings, keys are never removed or replaced
+func mergeMapSlices(user, def yaml.MapSlice, prefix string) (yaml.MapSlice, []string) {
+	missing := []string{}
+	for _, item := range def {
+		key := fmt.Sprint(item.Key)
+		index := -1
+		for i, existing := range user {
+			if fmt.Sprint(existing.Key) == key {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			user = append(user, item)
+			missing = append(missing, prefix+key)
+			continue
+		}
+		userValue, userIsMap := user[index].Value.(yaml.MapSlice)
+		defValue, defIsMap := item.Value.(yaml.MapSlice)
+		if userIsMap && defIsMap {
+			merged, nested := mergeMapSlices(userValue, defValue, prefix+key+".")
+			user[index].Value = merged
+			missing = append(missing, nested...)
+		}
+	}
+	return user, missing
+}
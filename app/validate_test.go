@@ -0,0 +1,177 @@
+package app
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"akinsho/gitgazer/domain"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeMapSlices(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		def      string
+		expected string
+		missing  []string
+	}{
+		{
+			name:     "keeps user values",
+			user:     "a: 1\nb: user\n",
+			def:      "a: 2\nb: default\n",
+			expected: "a: 1\nb: user\n",
+			missing:  []string{},
+		},
+		{
+			name:     "adds missing top level keys",
+			user:     "a: 1\n",
+			def:      "a: 2\nb: default\n",
+			expected: "a: 1\nb: default\n",
+			missing:  []string{"b"},
+		},
+		{
+			name:     "adds missing nested keys",
+			user:     "layout:\n  sidebar:\n    size: 2\n",
+			def:      "layout:\n  sidebar:\n    size: 1\n    panels: [starred]\n  main:\n    size: 3\n",
+			expected: "layout:\n  sidebar:\n    size: 2\n    panels:\n    - starred\n  main:\n    size: 3\n",
+			missing:  []string{"layout.sidebar.panels", "layout.main"},
+		},
+		{
+			name:     "keeps keys missing from the defaults",
+			user:     "a: 1\nextra: kept\n",
+			def:      "a: 2\n",
+			expected: "a: 1\nextra: kept\n",
+			missing:  []string{},
+		},
+		{
+			name:     "does not merge into a user value which is not a mapping",
+			user:     "keys: ctrl-q\n",
+			def:      "keys:\n  quit: ctrl-q\n",
+			expected: "keys: ctrl-q\n",
+			missing:  []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, def := yaml.MapSlice{}, yaml.MapSlice{}
+			if err := yaml.Unmarshal([]byte(test.user), &user); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(test.def), &def); err != nil {
+				t.Fatal(err)
+			}
+			merged, missing := mergeMapSlices(user, def, "")
+			output, err := yaml.Marshal(merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, output)
+			}
+			if !reflect.DeepEqual(missing, test.missing) {
+				t.Errorf("expected missing %v, got %v", test.missing, missing)
+			}
+		})
+	}
+}
+
+func TestMergeMissingKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		write   bool
+		changed bool
+	}{
+		{name: "reports missing keys without writing", config: "refresh:\n  interval: 5m\n", write: false},
+		{name: "writes missing keys", config: "refresh:\n  interval: 5m\n", write: true, changed: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			missing, err := mergeMissingKeys(path, test.write)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(missing) == 0 {
+				t.Fatal("expected missing keys")
+			}
+			for _, key := range missing {
+				if key == "refresh.interval" {
+					t.Errorf("refresh.interval is set but was reported as missing")
+				}
+			}
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := string(contents) != test.config; changed != test.changed {
+				t.Errorf("expected changed to be %t, got %t", test.changed, changed)
+			}
+			if !test.write {
+				return
+			}
+			backup, err := ioutil.ReadFile(path + ".bak")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(backup) != test.config {
+				t.Errorf("expected the backup to be the original config, got:\n%s", backup)
+			}
+			if !strings.Contains(string(contents), "interval: 5m") {
+				t.Errorf("expected the user's value to be kept, got:\n%s", contents)
+			}
+			config, err := loadUserConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again, _ := mergeMissingKeys(path, false); len(again) != 0 {
+				t.Errorf("expected no missing keys after writing, got %v", again)
+			}
+			if config.Refresh.Interval.String() != "5m0s" {
+				t.Errorf("expected refresh.interval to be 5m, got %s", config.Refresh.Interval)
+			}
+		})
+	}
+}
+
+func TestValidatePanels(t *testing.T) {
+	allowed := []domain.PanelName{domain.IssuesPanel, domain.PullRequestPanel}
+	tests := []struct {
+		name   string
+		panels []domain.PanelName
+		err    string
+	}{
+		{name: "allowed panels", panels: []domain.PanelName{domain.PullRequestPanel, domain.IssuesPanel}},
+		{name: "a single panel", panels: []domain.PanelName{domain.IssuesPanel}},
+		{name: "no panels", panels: []domain.PanelName{}, err: "at least one panel must be shown"},
+		{
+			name:   "panel from elsewhere",
+			panels: []domain.PanelName{domain.StarredRepositoriesPanel},
+			err:    "starred cannot be shown here, expected issues or prs",
+		},
+		{
+			name:   "duplicate panel",
+			panels: []domain.PanelName{domain.IssuesPanel, domain.IssuesPanel},
+			err:    "issues is shown more than once",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validatePanels(test.panels, allowed...)
+			if test.err == "" && err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"os"
+	"time"
+)
+
+// WatchFile polls the file every interval and calls changed whenever its modification time
+// or size changes, polling is used as editors often replace the file rather than writing to it.
+// Watching stops when the returned function is called.
+func WatchFile(path string, interval time.Duration, changed func()) (stop func()) {
+	done := make(chan struct{})
+	stop = func() { close(done) }
+	last, _ := os.Stat(path)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				// the file may briefly not exist while an editor replaces it
+				if err != nil {
+					continue
+				}
+				if last == nil || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size() {
+					last = info
+					changed()
+				}
+			}
+		}
+	}()
+	return stop
+}
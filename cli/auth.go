@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"akinsho/gitgazer/api"
+	"akinsho/gitgazer/app"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:        "auth",
+		usage:       "auth <status|login|logout>",
+		description: "Show who you are logged in as, log in again or delete the saved token",
+		requires:    requiresConfig,
+		run:         runAuth,
+	})
+}
+
+func runAuth(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "status":
+		return authStatus(ctx.Config)
+	case "login":
+		if err := ctx.Config.Login(); err != nil {
+			return err
+		}
+		return authStatus(ctx.Config)
+	case "logout":
+		return authLogout(ctx.Config)
+	}
+	return fmt.Errorf("unknown auth command %q, usage: gitgazer %s", args[0], cmd.usage)
+}
+
+func authStatus(config *app.Config) error {
+	if config.Token == nil {
+		err := config.LoadToken(false)
+		if errors.Is(err, app.ErrNotLoggedIn) {
+			fmt.Println("Not logged in, run gitgazer auth login")
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	client, err := api.Setup(config.Host, config.Token)
+	if err != nil {
+		return err
+	}
+	viewer, err := client.Viewer()
+	if errors.Is(err, api.ErrUnauthorized) {
+		return fmt.Errorf("the token from %s was rejected by github, run gitgazer auth login", config.TokenSource)
+	} else if err != nil {
+		return fmt.Errorf("failed to validate the token from %s: %w", config.TokenSource, err)
+	}
+	fmt.Printf(
+		"Logged in to %s as %s using the token from %s (profile %s)\n",
+		config.Host,
+		viewer.Login,
+		config.TokenSource,
+		config.Profile,
+	)
+	if viewer.Scopes == nil {
+		fmt.Println("Scopes: unknown, fine-grained tokens do not report their scopes")
+		return nil
+	}
+	fmt.Printf("Scopes: %s\n", strings.Join(viewer.Scopes, ", "))
+	if missing := viewer.MissingScopes(api.RequiredScopes); len(missing) > 0 {
+		fmt.Printf("Missing scopes: %s\n", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func authLogout(config *app.Config) error {
+	if err := config.Logout(); err != nil {
+		return err
+	}
+	fmt.Println("Deleted the saved access token")
+	if err := config.LoadToken(false); err == nil {
+		fmt.Printf("A token is still available from %s and will be used until it is removed\n", config.TokenSource)
+	} else if !errors.Is(err, app.ErrNotLoggedIn) {
+		return err
+	}
+	return nil
+}
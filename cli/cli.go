@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"akinsho/gitgazer/app"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// requirement is how much of the application is set up before a command runs
+type requirement int
+
+const (
+	// requiresSession commands receive an authenticated API client and the database
+	requiresSession requirement = iota
+	// requiresConfig commands run before the user is authenticated so the context
+	// they receive only has a Config
+	requiresConfig
+	// requiresNothing commands receive a Config which has been located but not loaded
+	requiresNothing
+)
+
+// command is a non-interactive subcommand e.g. gitgazer list
+type command struct {
+	name        string
+	usage       string
+	description string
+	requires    requirement
+	run         func(cmd *command, ctx *app.Context, args []string) error
+}
+
+var commands = map[string]*command{}
+
+func register(cmd *command) {
+	commands[cmd.name] = cmd
+}
+
+// IsCommand returns true if the name matches a registered subcommand
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// RequiresAuth returns true if the subcommand needs an authenticated API client and database
+func RequiresAuth(name string) bool {
+	cmd, ok := commands[name]
+	return !ok || cmd.requires == requiresSession
+}
+
+// RequiresConfig returns true if the subcommand needs the config to have been loaded
+func RequiresConfig(name string) bool {
+	cmd, ok := commands[name]
+	return !ok || cmd.requires != requiresNothing
+}
+
+// Run executes the subcommand named by the first argument
+func Run(ctx *app.Context, args []string) error {
+	if len(args) == 0 {
+		Usage(os.Stdout)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		Usage(os.Stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	err := cmd.run(cmd, ctx, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// Usage prints the list of available subcommands
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gitgazer [flags] [command]")
+	fmt.Fprintln(w, "\nRunning gitgazer without a command opens the TUI.")
+	fmt.Fprintln(w, "\nCommands:")
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.usage, cmd.description)
+	}
+	tw.Flush()
+}
+
+// newFlagSet creates the flag set for a subcommand
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gitgazer %s\n\n%s\n", cmd.usage, cmd.description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the flags allowing them to be interspersed with positional arguments
+// e.g. gitgazer stars owner/name --window 30d, the positional arguments are returned
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// expectArgs returns an error if the number of positional arguments is incorrect
+func expectArgs(cmd *command, args []string, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("usage: gitgazer %s", cmd.usage)
+	}
+	return nil
+}
+
+func fullName(owner, name string) string {
+	return strings.Join([]string{owner, name}, "/")
+}
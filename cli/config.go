@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"akinsho/gitgazer/app"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:        "config",
+		usage:       "config <check [--fix]|path>",
+		description: "Check the config file for problems or print its location",
+		requires:    requiresNothing,
+		run:         runConfig,
+	})
+}
+
+func runConfig(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	fix := fs.Bool("fix", false, "add the missing settings to the config file using their defaults")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	path := ctx.Config.Path()
+	switch args[0] {
+	case "path":
+		fmt.Println(path)
+		return nil
+	case "check":
+		missing, err := app.CheckConfig(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s is valid\n", path)
+		if len(missing) == 0 {
+			return nil
+		}
+		if !*fix {
+			fmt.Printf(
+				"The following settings are missing and use their defaults, run gitgazer config check --fix to add them:\n  %s\n",
+				strings.Join(missing, "\n  "),
+			)
+			return nil
+		}
+		if _, err := app.AddMissingKeys(path); err != nil {
+			return fmt.Errorf("failed to add the missing settings to %s: %w", path, err)
+		}
+		fmt.Printf(
+			"Added the following settings, the previous version was saved to %s.bak:\n  %s\n",
+			path,
+			strings.Join(missing, "\n  "),
+		)
+		return nil
+	}
+	return fmt.Errorf("unknown config command %q, usage: gitgazer %s", args[0], cmd.usage)
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"akinsho/gitgazer/api"
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/github"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// minimumRateLimit is the number of API points that must be left before a sync is started,
+// below this the daemon waits for the rate limit to reset
+const minimumRateLimit = 100
+
+func init() {
+	register(&command{
+		name:        "daemon",
+		usage:       "daemon [--interval 1h]",
+		description: "Periodically sync favourites and starred repositories until stopped",
+		run:         runDaemon,
+	})
+}
+
+func runDaemon(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	interval := fs.Duration("interval", ctx.Config.UserConfig.Daemon.Interval, "time to wait between syncs")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 0); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero, got %s", *interval)
+	}
+	defer ctx.DB.Close()
+
+	done, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	logger := log.New(os.Stderr, "gitgazer: ", log.LstdFlags)
+	logger.Printf("starting daemon, syncing every %s", *interval)
+	for {
+		if err := waitForRateLimit(done, ctx, logger); errors.Is(err, api.ErrUnauthorized) {
+			return fmt.Errorf("%w, run gitgazer auth login", err)
+		} else if err != nil {
+			logger.Printf("failed to check rate limit: %s", err)
+		}
+		if done.Err() == nil {
+			syncAll(ctx, logger)
+		}
+		select {
+		case <-done.Done():
+			logger.Println("stopping daemon")
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// syncAll syncs favourites and starred repositories, failures are logged rather than returned
+// so that a transient error does not stop the daemon
+func syncAll(ctx *app.Context, logger *log.Logger) {
+	favourites, err := github.Sync(ctx)
+	if err != nil {
+		logger.Printf("failed to sync favourites: %s", err)
+	} else {
+		logger.Printf("synced %d favourites", len(favourites))
+	}
+	starred, err := github.SyncStarred(ctx)
+	if err != nil {
+		logger.Printf("failed to sync starred repositories: %s", err)
+	} else {
+		logger.Printf("synced %d starred repositories", len(starred))
+	}
+}
+
+// waitForRateLimit blocks until the rate limit has reset if too few requests remain
+func waitForRateLimit(done context.Context, ctx *app.Context, logger *log.Logger) error {
+	limit, err := ctx.Client.RateLimit()
+	if err != nil {
+		return err
+	}
+	if limit.Remaining >= minimumRateLimit {
+		return nil
+	}
+	wait := time.Until(limit.ResetAt)
+	logger.Printf("only %d of %d requests remaining, waiting %s for the rate limit to reset",
+		limit.Remaining,
+		limit.Limit,
+		wait.Round(time.Second),
+	)
+	select {
+	case <-done.Done():
+	case <-time.After(wait):
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/github"
+	"fmt"
+	"io"
+	"os"
+)
+
+func init() {
+	register(&command{
+		name:        "list",
+		usage:       "list [--remote] [--format table|json|csv]",
+		description: "List your favourite repositories",
+		run:         runList,
+	})
+	register(&command{
+		name:        "add",
+		usage:       "add [--format table|json|csv] <owner/repo>",
+		description: "Add a repository to your favourites",
+		run:         runAdd,
+	})
+	register(&command{
+		name:        "remove",
+		usage:       "remove [--format table|json|csv] <owner/repo>",
+		description: "Remove a repository from your favourites",
+		run:         runRemove,
+	})
+	register(&command{
+		name:        "export",
+		usage:       "export [--format json|yaml|csv] <file|->",
+		description: "Export your favourites to a file or stdout",
+		run:         runExport,
+	})
+	register(&command{
+		name:        "import",
+		usage:       "import [--format json|yaml|csv] <file|->",
+		description: "Import favourites from a file or stdin merging them with your existing favourites",
+		run:         runImport,
+	})
+}
+
+func runList(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := formatFlag(fs)
+	remote := fs.Bool("remote", false, "fetch the latest star, issue and pull request counts from github")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 0); err != nil {
+		return err
+	}
+	if _, err := parseOutputFormat(*format); err != nil {
+		return err
+	}
+	if *remote {
+		repos, err := github.RetrieveFavouriteRepositories(ctx)
+		if err != nil {
+			return err
+		}
+		records := []repositoryRecord{}
+		for _, repo := range repos {
+			records = append(records, newRepositoryRecord(repo))
+		}
+		return writeRecords(os.Stdout, *format, records)
+	}
+	favourites, err := github.ListSavedFavourites(ctx)
+	if err != nil {
+		return err
+	}
+	records := []favouriteRecord{}
+	for _, favourite := range favourites {
+		records = append(records, newFavouriteRecord(favourite))
+	}
+	return writeRecords(os.Stdout, *format, records)
+}
+
+func runAdd(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := formatFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	if _, err := parseOutputFormat(*format); err != nil {
+		return err
+	}
+	owner, name, err := common.ParseRepositoryName(args[0])
+	if err != nil {
+		return err
+	}
+	repo, err := github.AddFavouriteByName(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	return writeRecords(os.Stdout, *format, []repositoryRecord{newRepositoryRecord(repo)})
+}
+
+func runRemove(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := formatFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	if _, err := parseOutputFormat(*format); err != nil {
+		return err
+	}
+	owner, name, err := common.ParseRepositoryName(args[0])
+	if err != nil {
+		return err
+	}
+	favourite, err := github.RemoveFavouriteByName(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	return writeRecords(os.Stdout, *format, []favouriteRecord{newFavouriteRecord(favourite)})
+}
+
+// exportFormat returns the format specified by the user or derives it from the path
+func exportFormat(path, name string) (github.ExportFormat, error) {
+	if name != "" {
+		return github.ParseExportFormat(name)
+	}
+	if path == "-" {
+		return github.JSONFormat, nil
+	}
+	return github.ExportFormatFromPath(path)
+}
+
+func runExport(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := fs.String("format", "", "format of the export (default: derived from the file extension)")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	path := args[0]
+	f, err := exportFormat(path, *format)
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		return github.ExportFavourites(ctx, os.Stdout, f)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := github.ExportFavourites(ctx, file, f); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func runImport(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := fs.String("format", "", "format of the import (default: derived from the file extension)")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	path := args[0]
+	f, err := exportFormat(path, *format)
+	if err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	added, err := github.ImportFavourites(ctx, r, f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d new favourites\n", added)
+	return nil
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"akinsho/gitgazer/domain"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type outputFormat string
+
+const (
+	tableOutput outputFormat = "table"
+	jsonOutput  outputFormat = "json"
+	csvOutput   outputFormat = "csv"
+)
+
+// record is a row of output with a stable schema, the json field names match the columns
+type record interface {
+	columns() []string
+	values() []string
+}
+
+// formatFlag adds the --format flag to the command's flag set
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", string(tableOutput), "output format: table, json or csv")
+}
+
+func parseOutputFormat(name string) (outputFormat, error) {
+	switch outputFormat(strings.ToLower(name)) {
+	case tableOutput:
+		return tableOutput, nil
+	case jsonOutput:
+		return jsonOutput, nil
+	case csvOutput:
+		return csvOutput, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, expected one of table, json or csv", name)
+}
+
+// writeRecords writes the records in the given format, json is always written as an array
+func writeRecords[T record](w io.Writer, name string, records []T) error {
+	format, err := parseOutputFormat(name)
+	if err != nil {
+		return err
+	}
+	var empty T
+	switch format {
+	case jsonOutput:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if records == nil {
+			records = []T{}
+		}
+		return e.Encode(records)
+	case csvOutput:
+		c := csv.NewWriter(w)
+		if err := c.Write(empty.columns()); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := c.Write(r.values()); err != nil {
+				return err
+			}
+		}
+		c.Flush()
+		return c.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(empty.columns(), "\t")))
+		for _, r := range records {
+			fmt.Fprintln(tw, strings.Join(r.values(), "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// favouriteRecord is the output schema for a saved favourite
+type favouriteRecord struct {
+	RepoID      string `json:"repo_id"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newFavouriteRecord(f *domain.FavouriteRepository) favouriteRecord {
+	return favouriteRecord{
+		RepoID:      f.RepoID,
+		Owner:       f.Owner,
+		Name:        f.Name,
+		Description: f.Description,
+	}
+}
+
+func (favouriteRecord) columns() []string {
+	return []string{"repo_id", "owner", "name", "description"}
+}
+
+func (r favouriteRecord) values() []string {
+	return []string{r.RepoID, r.Owner, r.Name, r.Description}
+}
+
+// repositoryRecord is the output schema for a repository fetched from github
+type repositoryRecord struct {
+	RepoID           string `json:"repo_id"`
+	Owner            string `json:"owner"`
+	Name             string `json:"name"`
+	Stars            int    `json:"stars"`
+	OpenIssues       int    `json:"open_issues"`
+	OpenPullRequests int    `json:"open_pull_requests"`
+	URL              string `json:"url"`
+}
+
+func newRepositoryRecord(repo *domain.Repository) repositoryRecord {
+	return repositoryRecord{
+		RepoID:           repo.GetID(),
+		Owner:            repo.GetOwnerLogin(),
+		Name:             repo.GetName(),
+		Stars:            repo.GetStargazerCount(),
+		OpenIssues:       repo.GetOpenIssueCount(),
+		OpenPullRequests: repo.GetPullRequestCount(),
+		URL:              repo.URL,
+	}
+}
+
+func (repositoryRecord) columns() []string {
+	return []string{"repo_id", "owner", "name", "stars", "open_issues", "open_pull_requests", "url"}
+}
+
+func (r repositoryRecord) values() []string {
+	return []string{
+		r.RepoID,
+		r.Owner,
+		r.Name,
+		strconv.Itoa(r.Stars),
+		strconv.Itoa(r.OpenIssues),
+		strconv.Itoa(r.OpenPullRequests),
+		r.URL,
+	}
+}
+
+// starsRecord is the output schema for the change in a repository's stars over a window,
+// the change and since fields are null if no snapshots were recorded within the window
+type starsRecord struct {
+	RepoID string     `json:"repo_id"`
+	Owner  string     `json:"owner"`
+	Name   string     `json:"name"`
+	Stars  int        `json:"stars"`
+	Window string     `json:"window"`
+	Change *int       `json:"change"`
+	Since  *time.Time `json:"since"`
+}
+
+func newStarsRecord(history *domain.StarHistory, window string) starsRecord {
+	repo := history.Repository
+	r := starsRecord{
+		RepoID: repo.GetID(),
+		Owner:  repo.GetOwnerLogin(),
+		Name:   repo.GetName(),
+		Stars:  repo.GetStargazerCount(),
+		Window: window,
+	}
+	if history.Oldest != nil {
+		change := history.GetDelta()
+		since := history.Oldest.RecordedAt.UTC()
+		r.Change = &change
+		r.Since = &since
+	}
+	return r
+}
+
+func (starsRecord) columns() []string {
+	return []string{"repo_id", "owner", "name", "stars", "window", "change", "since"}
+}
+
+func (r starsRecord) values() []string {
+	change, since := "", ""
+	if r.Change != nil {
+		change = fmt.Sprintf("%+d", *r.Change)
+	}
+	if r.Since != nil {
+		since = r.Since.Format(time.RFC3339)
+	}
+	return []string{r.RepoID, r.Owner, r.Name, strconv.Itoa(r.Stars), r.Window, change, since}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/github"
+	"os"
+)
+
+func init() {
+	register(&command{
+		name:        "sync",
+		usage:       "sync [--format table|json|csv]",
+		description: "Refresh your favourites from github and record their star counts",
+		run:         runSync,
+	})
+	register(&command{
+		name:        "stars",
+		usage:       "stars [--window 30d] [--format table|json|csv] <owner/repo>",
+		description: "Show how a repository's star count has changed within a window of time",
+		run:         runStars,
+	})
+}
+
+func runSync(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := formatFlag(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 0); err != nil {
+		return err
+	}
+	if _, err := parseOutputFormat(*format); err != nil {
+		return err
+	}
+	repos, err := github.Sync(ctx)
+	if err != nil {
+		return err
+	}
+	records := []repositoryRecord{}
+	for _, repo := range repos {
+		records = append(records, newRepositoryRecord(repo))
+	}
+	return writeRecords(os.Stdout, *format, records)
+}
+
+func runStars(cmd *command, ctx *app.Context, args []string) error {
+	fs := newFlagSet(cmd)
+	format := formatFlag(fs)
+	window := fs.String("window", "7d", "window of time to compare e.g. 24h, 7d, 4w")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(cmd, args, 1); err != nil {
+		return err
+	}
+	if _, err := parseOutputFormat(*format); err != nil {
+		return err
+	}
+	owner, name, err := common.ParseRepositoryName(args[0])
+	if err != nil {
+		return err
+	}
+	duration, err := common.ParseWindow(*window)
+	if err != nil {
+		return err
+	}
+	history, err := github.GetStarHistory(ctx, owner, name, duration)
+	if err != nil {
+		return err
+	}
+	return writeRecords(os.Stdout, *format, []starsRecord{newStarsRecord(history, *window)})
+}
@@ -2,9 +2,12 @@ package common
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -60,3 +63,103 @@ func RemoveIndex[T any](s []T, index int) []T {
 	ret = append(ret, s[:index]...)
 	return append(ret, s[index+1:]...)
 }
+
+// EditText opens the text in the user's $VISUAL or $EDITOR, falling back to vi, and returns
+// the edited text once the editor exits without its trailing newline
+func EditText(text string) (string, error) {
+	file, err := os.CreateTemp("", "gitgazer-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// the editor is run by the shell so that it can include arguments e.g. "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", file.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(edited), "\n"), nil
+}
+
+// ParseWindow parses a window of time such as "30d" or "2w", in addition to the units
+// understood by time.ParseDuration "d" (days) and "w" (weeks) are supported
+func ParseWindow(window string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if !strings.HasSuffix(window, suffix) {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSuffix(window, suffix))
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid window %q", window)
+		}
+		return time.Duration(count) * unit, nil
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+	return duration, nil
+}
+
+// ParseRepositoryName splits a repository name in the form "owner/name"
+func ParseRepositoryName(fullName string) (owner string, name string, err error) {
+	parts := strings.Split(strings.TrimSpace(fullName), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository %q, expected owner/name", fullName)
+	}
+	return parts[0], parts[1], nil
+}
+
+// FuzzyMatch returns true if the characters of the pattern appear in order in the text ignoring
+// case. Matches of consecutive characters and of the start of words score higher.
+func FuzzyMatch(pattern, text string) (int, bool) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	text = strings.ToLower(text)
+	if pattern == "" {
+		return 0, true
+	}
+	score := 0
+	consecutive := 0
+	runes := []rune(pattern)
+	p := 0
+	var previous rune = ' '
+	for _, r := range text {
+		if p < len(runes) && r == runes[p] {
+			consecutive++
+			score += consecutive
+			if previous == ' ' || previous == '_' || previous == '-' {
+				score += 2
+			}
+			p++
+		} else {
+			consecutive = 0
+		}
+		previous = r
+	}
+	if p < len(runes) {
+		return 0, false
+	}
+	return score, true
+}
@@ -1,7 +1,12 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 type PanelName int64
@@ -33,11 +38,27 @@ func (p *PanelName) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	case "favourites":
 		*p = FavouriteRepositoriesPanel
 	default:
-		*p = UnknownPanel
+		return unmarshalError(unmarshal, "unknown panel %q, expected one of prs, issues, starred or favourites", panelName)
 	}
 	return nil
 }
 
+// unmarshalError returns the message as a yaml.TypeError prefixed with the line number of
+// the value being unmarshalled so it is reported along with the rest of the problems in the
+// file, any other error stops the decoding.
+func unmarshalError(unmarshal func(interface{}) error, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	// a scalar can never be unmarshalled into a map, the error is only used for its line number
+	var probe map[string]struct{}
+	var typeErr *yaml.TypeError
+	if err := unmarshal(&probe); errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		if line, _, ok := strings.Cut(typeErr.Errors[0], ":"); ok && strings.HasPrefix(line, "line ") {
+			message = line + ": " + message
+		}
+	}
+	return &yaml.TypeError{Errors: []string{message}}
+}
+
 func (name PanelName) String() string {
 	switch name {
 	case PullRequestPanel:
@@ -59,11 +80,11 @@ type Repo interface {
 }
 
 type FavouriteRepository struct {
-	ID          int64
-	RepoID      string
-	Owner       string
-	Description string
-	Name        string
+	ID          int64  `json:"-" yaml:"-"`
+	RepoID      string `json:"repo_id" yaml:"repo_id"`
+	Owner       string `json:"owner" yaml:"owner"`
+	Description string `json:"description" yaml:"description"`
+	Name        string `json:"name" yaml:"name"`
 }
 
 func (r *FavouriteRepository) GetDescription() string {
@@ -130,6 +151,9 @@ type Repository struct {
 	Issues struct {
 		Nodes []*Issue
 	} `graphql:"issues(first: $issueCount, orderBy: $issuesOrderBy)"`
+	OpenIssues struct {
+		TotalCount int
+	} `graphql:"openIssues: issues(states: OPEN)"`
 }
 
 //--------------------------------------------------------------------------------------------------
@@ -178,6 +202,20 @@ func (r *Repository) GetIssueCount() int {
 	return len(r.Issues.Nodes)
 }
 
+func (r *Repository) GetOpenIssueCount() int {
+	if r == nil {
+		return 0
+	}
+	return r.OpenIssues.TotalCount
+}
+
+func (r *Repository) GetOwnerLogin() string {
+	if r == nil || r.Owner == nil {
+		return ""
+	}
+	return r.Owner.Login
+}
+
 func (r *Repository) GetIssues() []*Issue {
 	if r == nil {
 		return []*Issue{}
@@ -213,3 +251,135 @@ func (i *Issue) GetNumber() int {
 	}
 	return i.Number
 }
+
+type WorkRepository struct {
+	NameWithOwner string
+}
+
+// WorkItem is a pull request or issue returned from a search of the viewer's work
+type WorkItem struct {
+	Title      string
+	Number     int
+	URL        string
+	State      string
+	UpdatedAt  time.Time
+	Repository WorkRepository
+}
+
+// Work contains the pull requests and issues involving the current user across all repositories
+type Work struct {
+	Authored        []*WorkItem
+	ReviewRequested []*WorkItem
+	Assigned        []*WorkItem
+}
+
+// RepositoryWork is a list of work items belonging to a single repository
+type RepositoryWork struct {
+	Name  string
+	Items []*WorkItem
+}
+
+func (w *WorkItem) GetRepositoryName() string {
+	if w == nil {
+		return ""
+	}
+	return w.Repository.NameWithOwner
+}
+
+// Tag is a user defined group that favourite repositories can be assigned to
+type Tag struct {
+	ID   int64
+	Name string
+}
+
+// Note is a personal note the user has written about a repository
+type Note struct {
+	RepoID    string
+	Body      string
+	UpdatedAt time.Time
+}
+
+func (n *Note) GetBody() string {
+	if n == nil {
+		return ""
+	}
+	return n.Body
+}
+
+// StarSnapshot is a repository's star count at a point in time
+type StarSnapshot struct {
+	RepoID         string
+	StargazerCount int
+	RecordedAt     time.Time
+}
+
+// StarHistory is the change in a repository's star count over a window of time
+type StarHistory struct {
+	Repository *Repository
+	Window     time.Duration
+	// Oldest is the earliest snapshot recorded within the window, it is nil if
+	// no snapshots have been recorded
+	Oldest *StarSnapshot
+}
+
+// GetDelta returns the number of stars gained (or lost) since the oldest snapshot
+func (h *StarHistory) GetDelta() int {
+	if h == nil || h.Oldest == nil {
+		return 0
+	}
+	return h.Repository.GetStargazerCount() - h.Oldest.StargazerCount
+}
+
+// CacheEntry is a payload fetched from github and saved locally
+type CacheEntry struct {
+	Key       string
+	Payload   []byte
+	FetchedAt time.Time
+}
+
+// RateLimit is the state of the user's github API rate limit
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Viewer is the user the access token belongs to and the OAuth scopes it was granted,
+// fine-grained tokens do not report scopes so Scopes is nil for them
+type Viewer struct {
+	Login  string
+	Scopes []string
+}
+
+// impliedScopes are the scopes granted by a broader scope
+var impliedScopes = map[string][]string{
+	"read:org": {"write:org", "admin:org"},
+}
+
+// MissingScopes returns the required scopes which the token has not been granted
+func (v *Viewer) MissingScopes(required []string) []string {
+	if v.Scopes == nil {
+		return nil
+	}
+	granted := map[string]bool{}
+	for _, scope := range v.Scopes {
+		granted[scope] = true
+	}
+	missing := []string{}
+	for _, scope := range required {
+		if granted[scope] {
+			continue
+		}
+		found := false
+		for _, broader := range impliedScopes[scope] {
+			if granted[broader] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
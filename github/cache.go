@@ -0,0 +1,110 @@
+package github
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/domain"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	favouritesCacheKey = "favourites"
+	starredCacheKey    = "starred"
+)
+
+// cacheRepositories saves the repositories fetched from github under the key
+func cacheRepositories(ctx *app.Context, key string, repos []*domain.Repository) error {
+	payload, err := json.Marshal(repos)
+	if err != nil {
+		return err
+	}
+	return ctx.DB.PutCache(key, payload, time.Now())
+}
+
+// cacheLoadedRepositories caches the repositories after they have been loaded, a failure
+// is only logged as the repositories can be shown without the cache e.g. when it is read only
+func cacheLoadedRepositories(ctx *app.Context, key string, repos []*domain.Repository) {
+	if err := cacheRepositories(ctx, key, repos); err != nil && ctx.Logger != nil {
+		ctx.Logger.Warn(fmt.Sprintf("failed to cache the %s repositories: %s", key, err))
+	}
+}
+
+// cachedRepositories returns the repositories saved under the key if they were fetched
+// within the cache's time to live, the second return value is false if there was no usable entry
+func cachedRepositories(ctx *app.Context, key string) ([]*domain.Repository, bool, error) {
+	ttl := ctx.Config.UserConfig.Cache.TTL
+	if ttl <= 0 {
+		return nil, false, nil
+	}
+	entry, err := ctx.DB.GetCache(key)
+	if err != nil || entry == nil {
+		return nil, false, err
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return nil, false, nil
+	}
+	repos := []*domain.Repository{}
+	if err := json.Unmarshal(entry.Payload, &repos); err != nil {
+		return nil, false, err
+	}
+	return repos, true, nil
+}
+
+// LoadFavouriteRepositories returns the cached favourites if they are fresh
+// otherwise they are fetched from github, calling progress as each arrives, and cached
+func LoadFavouriteRepositories(
+	ctx *app.Context,
+	progress FavouritesProgress,
+) ([]*domain.Repository, error) {
+	repos, ok, err := cachedRepositories(ctx, favouritesCacheKey)
+	if err == nil && ok {
+		return repos, nil
+	}
+	repos, err = RetrieveFavouriteRepositoriesWithProgress(ctx, progress)
+	if err != nil {
+		return nil, err
+	}
+	cacheLoadedRepositories(ctx, favouritesCacheKey, repos)
+	return repos, nil
+}
+
+// RefreshFavouriteRepositories fetches the favourites from github even if the cached
+// copy is fresh and caches them
+func RefreshFavouriteRepositories(ctx *app.Context) ([]*domain.Repository, error) {
+	repos, err := RetrieveFavouriteRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cacheLoadedRepositories(ctx, favouritesCacheKey, repos)
+	return repos, nil
+}
+
+// LoadStarredRepositories returns the cached starred repositories if they are fresh
+// otherwise they are fetched from github and cached
+func LoadStarredRepositories(ctx *app.Context) ([]*domain.Repository, error) {
+	repos, ok, err := cachedRepositories(ctx, starredCacheKey)
+	if err == nil && ok {
+		return repos, nil
+	}
+	repos, err = ListStarredRepositories(ctx.Client)
+	if err != nil {
+		return nil, err
+	}
+	cacheLoadedRepositories(ctx, starredCacheKey, repos)
+	return repos, nil
+}
+
+// invalidateFavourites removes the cached favourites so they are fetched again after the
+// saved favourites have changed
+func invalidateFavourites(ctx *app.Context) error {
+	return ctx.DB.DeleteCache(favouritesCacheKey)
+}
+
+// InvalidateCache removes every cached list of repositories
+func InvalidateCache(ctx *app.Context) error {
+	if err := ctx.DB.DeleteCache(starredCacheKey); err != nil {
+		return err
+	}
+	return ctx.DB.DeleteCache(favouritesCacheKey)
+}
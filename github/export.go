@@ -0,0 +1,170 @@
+package github
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/domain"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type ExportFormat string
+
+const (
+	JSONFormat ExportFormat = "json"
+	YAMLFormat ExportFormat = "yaml"
+	CSVFormat  ExportFormat = "csv"
+)
+
+var csvHeader = []string{"repo_id", "owner", "name", "description"}
+
+// ParseExportFormat returns the export format matching the name e.g. "json"
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSONFormat, nil
+	case "yaml", "yml":
+		return YAMLFormat, nil
+	case "csv":
+		return CSVFormat, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, expected one of json, yaml or csv", name)
+}
+
+// ExportFormatFromPath derives the export format from the file's extension
+func ExportFormatFromPath(path string) (ExportFormat, error) {
+	return ParseExportFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ExportFavourites writes all saved favourites to the writer in the given format
+func ExportFavourites(ctx *app.Context, w io.Writer, format ExportFormat) error {
+	favourites, err := ListSavedFavourites(ctx)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case JSONFormat:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(favourites)
+	case YAMLFormat:
+		return yaml.NewEncoder(w).Encode(favourites)
+	case CSVFormat:
+		c := csv.NewWriter(w)
+		if err := c.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, f := range favourites {
+			if err := c.Write([]string{f.RepoID, f.Owner, f.Name, f.Description}); err != nil {
+				return err
+			}
+		}
+		c.Flush()
+		return c.Error()
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// ImportFavourites reads favourites in the given format from the reader and merges them
+// into the saved favourites by repository ID. Entries without an ID are looked up on github
+// by their owner and name. The number of newly added favourites is returned.
+func ImportFavourites(ctx *app.Context, r io.Reader, format ExportFormat) (int, error) {
+	favourites, err := decodeFavourites(r, format)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, favourite := range favourites {
+		repo, err := resolveFavourite(ctx, favourite)
+		if err != nil {
+			return added, err
+		}
+		existing, err := GetFavouriteByRepositoryID(ctx, repo.ID)
+		if err == nil && existing != nil {
+			continue
+		}
+		if _, err := ctx.DB.Insert(repo); err != nil {
+			return added, err
+		}
+		added++
+	}
+	if added > 0 {
+		return added, invalidateFavourites(ctx)
+	}
+	return added, nil
+}
+
+// resolveFavourite converts an imported favourite into a repository fetching it from github
+// if the repository ID is missing
+func resolveFavourite(
+	ctx *app.Context,
+	favourite *domain.FavouriteRepository,
+) (*domain.Repository, error) {
+	if favourite.RepoID != "" {
+		if favourite.Name == "" || favourite.Owner == "" {
+			return nil, fmt.Errorf("favourite %s is missing an owner or name", favourite.RepoID)
+		}
+		return &domain.Repository{
+			ID:          favourite.RepoID,
+			Name:        favourite.Name,
+			Description: favourite.Description,
+			Owner:       &domain.RepositoryOwner{Login: favourite.Owner},
+		}, nil
+	}
+	if favourite.Name == "" || favourite.Owner == "" {
+		return nil, fmt.Errorf("favourite is missing a repo_id, owner and name so cannot be imported")
+	}
+	repo, err := ctx.Client.FetchRepositoryByName(favourite.Name, favourite.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s/%s: %w", favourite.Owner, favourite.Name, err)
+	}
+	return repo, nil
+}
+
+func decodeFavourites(r io.Reader, format ExportFormat) ([]*domain.FavouriteRepository, error) {
+	favourites := []*domain.FavouriteRepository{}
+	switch format {
+	case JSONFormat:
+		if err := json.NewDecoder(r).Decode(&favourites); err != nil {
+			return nil, err
+		}
+	case YAMLFormat:
+		if err := yaml.NewDecoder(r).Decode(&favourites); err != nil && err != io.EOF {
+			return nil, err
+		}
+	case CSVFormat:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return favourites, nil
+		}
+		columns := map[string]int{}
+		for i, name := range records[0] {
+			columns[strings.TrimSpace(name)] = i
+		}
+		column := func(record []string, name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		for _, record := range records[1:] {
+			favourites = append(favourites, &domain.FavouriteRepository{
+				RepoID:      column(record, "repo_id"),
+				Owner:       column(record, "owner"),
+				Name:        column(record, "name"),
+				Description: column(record, "description"),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return favourites, nil
+}
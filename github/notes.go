@@ -0,0 +1,22 @@
+package github
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/domain"
+)
+
+// GetNote returns the user's note for the repository or nil if they haven't written one
+func GetNote(ctx *app.Context, repo *domain.Repository) (*domain.Note, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	return ctx.DB.GetNoteByRepoID(repo.ID)
+}
+
+// SaveNote saves the user's note for the repository, an empty note deletes it
+func SaveNote(ctx *app.Context, repo *domain.Repository, body string) error {
+	if repo == nil {
+		return nil
+	}
+	return ctx.DB.SetNote(repo.ID, body)
+}
@@ -5,6 +5,8 @@ import (
 	"akinsho/gitgazer/app"
 	"akinsho/gitgazer/common"
 	"akinsho/gitgazer/domain"
+	"sort"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -45,41 +47,67 @@ func ListStarredRepositories(client *api.Client) ([]*domain.Repository, error) {
 	return repos, nil
 }
 
-func fetchFavouriteRepo(
-	client *api.Client,
-	repo *domain.FavouriteRepository,
-	results chan *domain.Repository,
-) error {
-	r, err := client.FetchRepositoryByName(repo.Name, repo.Owner)
-	if err != nil {
-		return err
-	}
-	results <- r
-	return nil
-}
+// maxConcurrentRequests limits the number of requests made to github at once to avoid
+// triggering its secondary rate limits
+const maxConcurrentRequests = 5
+
+// FavouritesProgress is called with the favourites fetched so far, in the order they were
+// saved, and the total number being fetched
+type FavouritesProgress func(loaded []*domain.Repository, total int)
 
 func RetrieveFavouriteRepositories(ctx *app.Context) ([]*domain.Repository, error) {
+	return RetrieveFavouriteRepositoriesWithProgress(ctx, nil)
+}
+
+// RetrieveFavouriteRepositoriesWithProgress fetches every saved favourite from github calling
+// progress before the first request is made and again as each favourite arrives. The calls
+// to progress are made one at a time from the goroutines fetching the favourites.
+func RetrieveFavouriteRepositoriesWithProgress(
+	ctx *app.Context,
+	progress FavouritesProgress,
+) ([]*domain.Repository, error) {
 	saved, err := ListSavedFavourites(ctx)
 	if err != nil {
 		return nil, err
 	}
+	// results arrive in the order the requests complete so are put back in the order
+	// they were saved to keep the list stable between refreshes
+	order := map[string]int{}
+	for i, repo := range saved {
+		order[repo.RepoID] = i
+	}
+	var mu sync.Mutex
+	repos := []*domain.Repository{}
+	report := func() {
+		if progress != nil {
+			progress(append([]*domain.Repository{}, repos...), len(saved))
+		}
+	}
+	report()
 	g := new(errgroup.Group)
-	results := make(chan *domain.Repository, len(saved))
+	limit := make(chan struct{}, maxConcurrentRequests)
 	for _, repo := range saved {
 		repo := repo
 		g.Go(func() error {
-			return fetchFavouriteRepo(ctx.Client, repo, results)
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			r, err := ctx.Client.FetchRepositoryByName(repo.Name, repo.Owner)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			repos = append(repos, r)
+			sort.SliceStable(repos, func(i, j int) bool {
+				return order[repos[i].ID] < order[repos[j].ID]
+			})
+			report()
+			return nil
 		})
 	}
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
-	close(results)
-	repos := []*domain.Repository{}
-	for result := range results {
-		repos = append(repos, result)
-	}
-
 	return repos, nil
 }
 
@@ -106,7 +134,12 @@ func ListSavedFavourites(ctx *app.Context) (repos []*domain.FavouriteRepository,
 }
 
 func FavouriteSelectedRepo(ctx *app.Context) (err error) {
-	repo := ctx.State.Selected
+	return FavouriteRepository(ctx, ctx.State.Selected)
+}
+
+// FavouriteRepository saves the repository to the database and adds it to the list of
+// favourites in memory if they have already been loaded
+func FavouriteRepository(ctx *app.Context, repo *domain.Repository) (err error) {
 	if repo == nil {
 		return
 	}
@@ -114,9 +147,36 @@ func FavouriteSelectedRepo(ctx *app.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	if err := invalidateFavourites(ctx); err != nil {
+		return err
+	}
+	favourites := ctx.State.Favourites
+	if len(favourites) == 0 {
+		return nil
+	}
+	for _, favourite := range favourites {
+		if favourite.ID == repo.ID {
+			return nil
+		}
+	}
+	ctx.SetFavourites(append(favourites, repo))
 	return nil
 }
 
+// FetchRepository fetches the latest details of the repository from github
+func FetchRepository(client *api.Client, repo *domain.Repository) (*domain.Repository, error) {
+	return client.FetchRepositoryByName(repo.GetName(), repo.GetOwnerLogin())
+}
+
+// SearchRepositories searches github for repositories matching the query
+func SearchRepositories(client *api.Client, query string) ([]*domain.Repository, error) {
+	repos, err := client.SearchRepositories(query)
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
 func UnfavouriteSelected(ctx *app.Context, index int) (err error) {
 	repo := ctx.State.Selected
 	if repo == nil {
@@ -127,5 +187,5 @@ func UnfavouriteSelected(ctx *app.Context, index int) (err error) {
 	if err != nil {
 		return err
 	}
-	return nil
+	return invalidateFavourites(ctx)
 }
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"akinsho/gitgazer/domain"
+	"sort"
+	"strings"
+)
+
+// SortOrder is the order a list of repositories is shown in
+type SortOrder int
+
+const (
+	// LoadedOrder keeps the order the repositories were loaded in e.g. most recently starred first
+	LoadedOrder SortOrder = iota
+	// StarsOrder shows the repositories with the most stars first
+	StarsOrder
+	// NameOrder shows the repositories alphabetically by name
+	NameOrder
+)
+
+func (o SortOrder) String() string {
+	switch o {
+	case StarsOrder:
+		return "stars"
+	case NameOrder:
+		return "name"
+	default:
+		return "default"
+	}
+}
+
+// Next returns the order after o returning to LoadedOrder after the last one
+func (o SortOrder) Next() SortOrder {
+	if o == NameOrder {
+		return LoadedOrder
+	}
+	return o + 1
+}
+
+// SortRepositories returns a copy of the repositories in the order, repositories which
+// are equal keep their relative order
+func SortRepositories(repos []*domain.Repository, order SortOrder) []*domain.Repository {
+	sorted := make([]*domain.Repository, len(repos))
+	copy(sorted, repos)
+	switch order {
+	case StarsOrder:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].GetStargazerCount() > sorted[j].GetStargazerCount()
+		})
+	case NameOrder:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return strings.ToLower(sorted[i].GetName()) < strings.ToLower(sorted[j].GetName())
+		})
+	}
+	return sorted
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/domain"
+	"fmt"
+	"time"
+)
+
+// Sync fetches the latest details of every favourite from github, updates the saved copy
+// of each and records a snapshot of its star count. The state is not updated so that
+// callers can do so on the appropriate goroutine.
+func Sync(ctx *app.Context) ([]*domain.Repository, error) {
+	repos, err := RetrieveFavouriteRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if err := ctx.DB.UpdateFavourite(repo); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordStarSnapshots(ctx, repos); err != nil {
+		return nil, err
+	}
+	if err := cacheRepositories(ctx, favouritesCacheKey, repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// SyncStarred fetches the user's starred repositories from github, caches them
+// and records a snapshot of each one's star count
+func SyncStarred(ctx *app.Context) ([]*domain.Repository, error) {
+	repos, err := ListStarredRepositories(ctx.Client)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordStarSnapshots(ctx, repos); err != nil {
+		return nil, err
+	}
+	if err := cacheRepositories(ctx, starredCacheKey, repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func recordStarSnapshots(ctx *app.Context, repos []*domain.Repository) error {
+	now := time.Now()
+	for _, repo := range repos {
+		if err := ctx.DB.InsertStarSnapshot(repo.ID, repo.StargazerCount, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddFavouriteByName fetches the repository from github and saves it as a favourite
+func AddFavouriteByName(ctx *app.Context, owner, name string) (*domain.Repository, error) {
+	repo, err := ctx.Client.FetchRepositoryByName(name, owner)
+	if err != nil {
+		return nil, err
+	}
+	if err := FavouriteRepository(ctx, repo); err != nil {
+		return nil, err
+	}
+	if err := ctx.DB.InsertStarSnapshot(repo.ID, repo.StargazerCount, time.Now()); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// RemoveFavouriteByName removes the saved favourite with the matching owner and name
+func RemoveFavouriteByName(ctx *app.Context, owner, name string) (*domain.FavouriteRepository, error) {
+	favourite, err := ctx.DB.GetFavouriteByName(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if favourite == nil {
+		return nil, fmt.Errorf("%s/%s is not a favourite", owner, name)
+	}
+	if err := ctx.DB.DeleteByRepoID(favourite.RepoID); err != nil {
+		return nil, err
+	}
+	return favourite, invalidateFavourites(ctx)
+}
+
+// GetStarHistory compares the repository's current star count with the oldest snapshot
+// recorded within the window
+func GetStarHistory(
+	ctx *app.Context,
+	owner, name string,
+	window time.Duration,
+) (*domain.StarHistory, error) {
+	repo, err := ctx.Client.FetchRepositoryByName(name, owner)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := ctx.DB.ListStarSnapshots(repo.ID, time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	history := &domain.StarHistory{Repository: repo, Window: window}
+	if len(snapshots) > 0 {
+		history.Oldest = snapshots[0]
+	}
+	return history, nil
+}
+
+// RefreshRepository fetches the latest details of a single repository, records a snapshot
+// of its star count and invalidates the cached lists that may contain an older copy of it
+func RefreshRepository(ctx *app.Context, repo *domain.Repository) (*domain.Repository, error) {
+	updated, err := FetchRepository(ctx.Client, repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordStarSnapshots(ctx, []*domain.Repository{updated}); err != nil {
+		return nil, err
+	}
+	if err := InvalidateCache(ctx); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
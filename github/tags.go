@@ -0,0 +1,80 @@
+package github
+
+import (
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/domain"
+	"strings"
+)
+
+// ListTags returns the names of every tag that has been assigned to a favourite
+func ListTags(ctx *app.Context) ([]string, error) {
+	tags, err := ctx.DB.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	return tagNames(tags), nil
+}
+
+// GetFavouriteTags returns the names of the tags assigned to the repository
+func GetFavouriteTags(ctx *app.Context, repo *domain.Repository) ([]string, error) {
+	tags, err := ctx.DB.ListTagsByRepoID(repo.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return tagNames(tags), nil
+}
+
+// TagFavourite replaces the tags assigned to the repository
+func TagFavourite(ctx *app.Context, repo *domain.Repository, tags []string) error {
+	if repo == nil {
+		return nil
+	}
+	return ctx.DB.SetTags(repo.ID, tags)
+}
+
+// ParseTags splits a comma separated list of tags removing any blank entries
+func ParseTags(text string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(text, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// FilterFavouritesByTag returns only the repositories assigned to the tag,
+// if the tag is empty all repositories are returned
+func FilterFavouritesByTag(
+	ctx *app.Context,
+	repos []*domain.Repository,
+	tag string,
+) ([]*domain.Repository, error) {
+	if tag == "" {
+		return repos, nil
+	}
+	ids, err := ctx.DB.ListRepoIDsByTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	tagged := map[string]bool{}
+	for _, id := range ids {
+		tagged[id] = true
+	}
+	filtered := []*domain.Repository{}
+	for _, repo := range repos {
+		if tagged[repo.GetID()] {
+			filtered = append(filtered, repo)
+		}
+	}
+	return filtered, nil
+}
+
+func tagNames(tags []*domain.Tag) []string {
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"akinsho/gitgazer/api"
+	"akinsho/gitgazer/domain"
+	"sort"
+)
+
+// ListViewerWork fetches the pull requests and issues involving the current user
+// across all repositories
+// ```
+// query {
+//   authored: search(query: "is:open is:pr author:@me", type: ISSUE, first: 50) {
+//     nodes {
+//       ... on PullRequest {
+//         title
+//         number
+//         url
+//         repository {
+//           nameWithOwner
+//         }
+//       }
+//     }
+//   }
+//   reviewRequested: search(query: "is:open is:pr review-requested:@me", type: ISSUE, first: 50) {...}
+//   assigned: search(query: "is:open is:issue assignee:@me", type: ISSUE, first: 50) {...}
+// }
+// ```
+func ListViewerWork(client *api.Client) (*domain.Work, error) {
+	work, err := client.ListViewerWork()
+	if err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+// GroupWorkByRepository buckets work items by the repository they belong to, the groups
+// are sorted by repository name whilst the items retain their original order
+func GroupWorkByRepository(items []*domain.WorkItem) []*domain.RepositoryWork {
+	groups := []*domain.RepositoryWork{}
+	lookup := map[string]*domain.RepositoryWork{}
+	for _, item := range items {
+		name := item.GetRepositoryName()
+		group, ok := lookup[name]
+		if !ok {
+			group = &domain.RepositoryWork{Name: name}
+			lookup[name] = group
+			groups = append(groups, group)
+		}
+		group.Items = append(group.Items, item)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
@@ -0,0 +1,76 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Chord is a single key press e.g. j or ctrl-d
+type Chord struct {
+	Key  tcell.Key
+	Rune rune
+}
+
+// namedKeys maps the lower case names of special keys e.g. "ctrl-d" or "esc" to their key
+var namedKeys = func() map[string]tcell.Key {
+	keys := map[string]tcell.Key{}
+	for key, name := range tcell.KeyNames {
+		keys[strings.ToLower(name)] = key
+	}
+	keys["escape"] = tcell.KeyEscape
+	keys["shift-tab"] = tcell.KeyBacktab
+	keys["return"] = tcell.KeyEnter
+	return keys
+}()
+
+// ParseChord parses a chord written as a single character e.g. "j" or "?", or the name of a
+// special key e.g. "ctrl-d", "c-d", "esc" or "tab". Special keys are case insensitive.
+func ParseChord(value string) (Chord, error) {
+	if utf8.RuneCountInString(value) == 1 {
+		r, _ := utf8.DecodeRuneInString(value)
+		return Chord{Key: tcell.KeyRune, Rune: r}, nil
+	}
+	name := strings.ToLower(strings.TrimSpace(value))
+	if name == "space" {
+		return Chord{Key: tcell.KeyRune, Rune: ' '}, nil
+	}
+	if strings.HasPrefix(name, "c-") {
+		name = "ctrl-" + strings.TrimPrefix(name, "c-")
+	}
+	if key, ok := namedKeys[name]; ok {
+		return Chord{Key: key}, nil
+	}
+	return Chord{}, fmt.Errorf("unknown key %q", value)
+}
+
+// Matches returns true if the event was caused by pressing the chord
+func (c Chord) Matches(event *tcell.EventKey) bool {
+	if c.Key == tcell.KeyRune {
+		return event.Key() == tcell.KeyRune && event.Rune() == c.Rune
+	}
+	return event.Key() == c.Key
+}
+
+// Event returns the key event produced by pressing the chord
+func (c Chord) Event() *tcell.EventKey {
+	if c.Key == tcell.KeyRune {
+		return tcell.NewEventKey(tcell.KeyRune, c.Rune, tcell.ModNone)
+	}
+	return tcell.NewEventKey(c.Key, 0, tcell.ModNone)
+}
+
+func (c Chord) String() string {
+	if c.Key == tcell.KeyRune {
+		if c.Rune == ' ' {
+			return "Space"
+		}
+		return string(c.Rune)
+	}
+	if name, ok := tcell.KeyNames[c.Key]; ok {
+		return name
+	}
+	return fmt.Sprintf("Key(%d)", c.Key)
+}
@@ -0,0 +1,244 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Action is a named operation that can be bound to one or more chords
+type Action string
+
+const (
+	Quit            Action = "quit"
+	ToggleDashboard Action = "toggle_dashboard"
+	Search          Action = "search"
+	SwitchProfile   Action = "switch_profile"
+	CommandPalette  Action = "command_palette"
+	Help            Action = "help"
+	Messages        Action = "messages"
+	Close           Action = "close"
+	NextSection     Action = "next_section"
+	PreviousSection Action = "previous_section"
+	Down            Action = "down"
+	Up              Action = "up"
+	Left            Action = "left"
+	Right           Action = "right"
+	Open            Action = "open"
+	ReloadSelected  Action = "reload_selected"
+	ReloadList      Action = "reload_list"
+	ScrollDown      Action = "scroll_down"
+	ScrollUp        Action = "scroll_up"
+	NextTab         Action = "next_tab"
+	PreviousTab     Action = "previous_tab"
+	Sort            Action = "sort"
+	EditTags        Action = "edit_tags"
+	CycleGroup      Action = "cycle_group"
+	EditNote        Action = "edit_note"
+)
+
+// Context is where in the TUI a binding is active
+type Context string
+
+const (
+	// Global bindings are handled before any other binding
+	Global Context = "global"
+	// List bindings are active in every list e.g. the sidebar, search results or dashboard
+	List Context = "list"
+	// Sidebar bindings are active in the starred and favourites lists
+	Sidebar Context = "sidebar"
+	// Favourites bindings are active in the favourites list
+	Favourites Context = "favourites"
+)
+
+// Contexts are all of the contexts from the broadest to the narrowest
+var Contexts = []Context{Global, List, Sidebar, Favourites}
+
+// parents are the contexts which are also active whenever the context is active,
+// a chord can only be bound once across a context and its parents
+var parents = map[Context][]Context{
+	Global:     {},
+	List:       {Global},
+	Sidebar:    {Global, List},
+	Favourites: {Global, List, Sidebar},
+}
+
+// Includes returns true if bindings in the other context are active in this context
+func (c Context) Includes(other Context) bool {
+	if c == other {
+		return true
+	}
+	for _, parent := range parents[c] {
+		if parent == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Binding is the default chords and description of an action
+type Binding struct {
+	Action      Action
+	Context     Context
+	Description string
+	Keys        []string
+}
+
+// Defaults are the bindings used unless they are overridden in the user's config
+var Defaults = []Binding{
+	{Quit, Global, "Quit", []string{"ctrl-q"}},
+	{ToggleDashboard, Global, "Toggle your work", []string{"ctrl-w"}},
+	{Search, Global, "Search repositories", []string{"ctrl-f"}},
+	{SwitchProfile, Global, "Switch profile", []string{"ctrl-a"}},
+	{CommandPalette, Global, "Open the command palette", []string{":", "ctrl-k"}},
+	{Help, Global, "Show all keybindings", []string{"?"}},
+	{Messages, Global, "Show recent messages", []string{"M"}},
+	{Close, Global, "Close the current window", []string{"esc"}},
+	{NextSection, Global, "Focus the next section", []string{"tab"}},
+	{PreviousSection, Global, "Focus the previous section", []string{"backtab"}},
+	{Down, List, "Move down", []string{"j"}},
+	{Up, List, "Move up", []string{"k"}},
+	{Left, List, "Move left", []string{"h"}},
+	{Right, List, "Move right", []string{"l"}},
+	{Open, List, "Open in the browser", []string{"ctrl-o"}},
+	{ReloadSelected, Sidebar, "Reload the selected repository", []string{"r"}},
+	{ReloadList, Sidebar, "Reload the list", []string{"R"}},
+	{ScrollDown, Sidebar, "Scroll the details down", []string{"ctrl-d"}},
+	{ScrollUp, Sidebar, "Scroll the details up", []string{"ctrl-u"}},
+	{NextTab, Sidebar, "Show the next tab", []string{"ctrl-n"}},
+	{PreviousTab, Sidebar, "Show the previous tab", []string{"ctrl-p"}},
+	{Sort, Sidebar, "Sort by stars, name or the default order", []string{"s"}},
+	{EditTags, Favourites, "Tag the favourite", []string{"t"}},
+	{CycleGroup, Favourites, "Show the next tag", []string{"g"}},
+	{EditNote, Favourites, "Write a note", []string{"n"}},
+}
+
+// KeyList is the keys bound to an action in the user's config, it can be written as a
+// single key e.g. `quit: ctrl-q` or a list e.g. `down: [j, down]`
+type KeyList []string
+
+func (l *KeyList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var key string
+	if err := unmarshal(&key); err == nil {
+		*l = KeyList{key}
+		return nil
+	}
+	var keys []string
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	*l = keys
+	return nil
+}
+
+// Keymap is the chords bound to each action
+type Keymap struct {
+	bindings []Binding
+	chords   map[Action][]Chord
+}
+
+// New creates a keymap from the default bindings replacing the chords of any action
+// named in overrides. An error is returned for unknown actions or keys and for chords
+// bound to more than one action that can be active at the same time.
+func New(overrides map[string]KeyList) (*Keymap, error) {
+	k := &Keymap{chords: map[Action][]Chord{}}
+	known := map[Action]bool{}
+	for _, binding := range Defaults {
+		known[binding.Action] = true
+	}
+	for name := range overrides {
+		if !known[Action(name)] {
+			return nil, fmt.Errorf("keys: unknown action %q", name)
+		}
+	}
+	for _, binding := range Defaults {
+		if keys, ok := overrides[string(binding.Action)]; ok {
+			binding.Keys = keys
+		}
+		for _, key := range binding.Keys {
+			chord, err := ParseChord(key)
+			if err != nil {
+				return nil, fmt.Errorf("keys: %s: %w", binding.Action, err)
+			}
+			k.chords[binding.Action] = append(k.chords[binding.Action], chord)
+		}
+		k.bindings = append(k.bindings, binding)
+	}
+	if err := k.validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// validate returns an error if a chord is bound to two actions which are active together
+func (k *Keymap) validate() error {
+	conflicts := []string{}
+	for i, a := range k.bindings {
+		for _, b := range k.bindings[i+1:] {
+			if !overlaps(a.Context, b.Context) {
+				continue
+			}
+			for _, chord := range k.chords[a.Action] {
+				for _, other := range k.chords[b.Action] {
+					if chord == other {
+						conflicts = append(conflicts, fmt.Sprintf("%s is bound to both %s and %s", chord, a.Action, b.Action))
+					}
+				}
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("keys: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// overlaps returns true if both contexts can be active at the same time
+func overlaps(a, b Context) bool {
+	if a == b {
+		return true
+	}
+	for _, parent := range parents[a] {
+		if parent == b {
+			return true
+		}
+	}
+	for _, parent := range parents[b] {
+		if parent == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Is returns true if the event matches any of the chords bound to the action
+func (k *Keymap) Is(action Action, event *tcell.EventKey) bool {
+	for _, chord := range k.chords[action] {
+		if chord.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Chords returns the chords bound to the action
+func (k *Keymap) Chords(action Action) []Chord {
+	return k.chords[action]
+}
+
+// Label returns the chords bound to the action for display e.g. "j/Down"
+func (k *Keymap) Label(action Action) string {
+	names := []string{}
+	for _, chord := range k.chords[action] {
+		names = append(names, chord.String())
+	}
+	return strings.Join(names, "/")
+}
+
+// Bindings returns the active bindings in the order they are defined
+func (k *Keymap) Bindings() []Binding {
+	return k.bindings
+}
@@ -2,43 +2,103 @@ package main
 
 import (
 	"akinsho/gitgazer/app"
-	"akinsho/gitgazer/domain"
-	"akinsho/gitgazer/storage"
+	"akinsho/gitgazer/cli"
 	"akinsho/gitgazer/ui"
+	"flag"
+	"fmt"
 	"log"
-
-	"akinsho/gitgazer/api"
+	"os"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
+var configPath = flag.String(
+	"config",
+	"",
+	"path to the config file (default: $"+app.ConfigPathEnv+" or $XDG_CONFIG_HOME/gitgazer/config.yaml)",
+)
+
+var logLevel = flag.String(
+	"log-level",
+	"info",
+	"the lowest level of messages written to the log: debug, info, warn or error",
+)
+
+var profile = flag.String(
+	"profile",
+	"",
+	"name of the profile to use (default: $"+app.ProfileEnv+" or the profile set in the config file)",
+)
+
 func main() {
-	config, err := app.InitConfig()
+	flag.Usage = func() {
+		cli.Usage(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.Arg(0) == "help" {
+		cli.Usage(os.Stdout)
+		return
+	}
+	if flag.NArg() > 0 && !cli.IsCommand(flag.Arg(0)) {
+		cli.Usage(os.Stderr)
+		os.Exit(2)
+	}
+	level, err := app.ParseLogLevel(*logLevel)
 	if err != nil {
-		log.Panicln(err)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
 	}
-	client, err := api.Setup(config.Token)
+
+	config, err := app.NewConfig(app.ConfigOptions{Path: *configPath, Profile: *profile})
 	if err != nil {
 		log.Panicln(err)
 	}
-	db, err := storage.Setup(config.StoragePath)
+	if flag.NArg() > 0 && !cli.RequiresConfig(flag.Arg(0)) {
+		runCommand(app.NewContext(config, nil, nil))
+		return
+	}
+	if err := config.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	logger := app.NewLogger(level)
+	logPath := config.LogPath
+	if flag.Arg(0) == "daemon" {
+		logPath = config.DaemonLogPath
+	}
+	if err := logger.OpenFile(logPath); err != nil {
+		fmt.Fprintln(os.Stderr, "warning: the log will not be saved:", err)
+	}
+	defer logger.Close()
+	if flag.NArg() > 0 && !cli.RequiresAuth(flag.Arg(0)) {
+		runCommand(app.NewContext(config, nil, nil))
+		return
+	}
+	context, err := app.Open(config)
 	if err != nil {
-		log.Panicln(err)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
 	}
+	context.SetLogger(logger)
+	logger.Info(fmt.Sprintf("started with the %s profile", config.Profile))
 
-	state := &app.State{
-		Favourites: []*domain.Repository{},
-		Starred:    []*domain.Repository{},
-		Selected:   nil,
-	}
-	context := &app.Context{
-		Client: client,
-		Config: config,
-		DB:     db,
-		State:  state,
+	if flag.NArg() > 0 {
+		runCommand(context)
+		return
 	}
 
 	if err := ui.Setup(context); err != nil {
 		log.Panicln(err)
 	}
 }
+
+// runCommand runs the subcommand named by the arguments exiting if it fails
+func runCommand(context *app.Context) {
+	if err := cli.Run(context, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"akinsho/gitgazer/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PutCache saves the payload under the key replacing any previous entry
+func (db *Database) PutCache(key string, payload []byte, fetchedAt time.Time) error {
+	_, err := db.cacheDB.Exec(
+		"INSERT OR REPLACE INTO cache (key, payload, fetched_at) VALUES (?, ?, ?);",
+		key,
+		payload,
+		fetchedAt.UTC(),
+	)
+	return err
+}
+
+// GetCache returns the entry saved under the key or nil if there isn't one
+func (db *Database) GetCache(key string) (*domain.CacheEntry, error) {
+	row := db.cacheDB.QueryRow("SELECT key, payload, fetched_at FROM cache WHERE key = ?;", key)
+	entry := &domain.CacheEntry{}
+	if err := row.Scan(&entry.Key, &entry.Payload, &entry.FetchedAt); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteCache removes the entry saved under the key
+func (db *Database) DeleteCache(key string) error {
+	_, err := db.cacheDB.Exec("DELETE FROM cache WHERE key = ?;", key)
+	return err
+}
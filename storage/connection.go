@@ -10,6 +10,9 @@ import (
 
 type Database struct {
 	sqlDB *sql.DB
+	// cacheDB holds payloads fetched from github, it is kept separate from the user's data
+	// so that it can live in the cache directory and be deleted at any time
+	cacheDB *sql.DB
 }
 
 const create string = `
@@ -21,15 +24,91 @@ const create string = `
 	description TEXT
   );`
 
-func Setup(path string) (*Database, error) {
-	db, err := sql.Open("sqlite3", path)
+const createTags string = `
+  CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER NOT NULL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+  );`
+
+const createRepositoryTags string = `
+  CREATE TABLE IF NOT EXISTS repository_tags (
+	repo_id STRING NOT NULL,
+	tag_id INTEGER NOT NULL,
+	PRIMARY KEY (repo_id, tag_id)
+  );`
+
+const createRepositoryNotes string = `
+  CREATE TABLE IF NOT EXISTS repository_notes (
+	repo_id STRING NOT NULL PRIMARY KEY,
+	body TEXT NOT NULL,
+	updated_at DATETIME NOT NULL
+  );`
+
+const createStarSnapshots string = `
+  CREATE TABLE IF NOT EXISTS star_snapshots (
+	id INTEGER NOT NULL PRIMARY KEY,
+	repo_id STRING NOT NULL,
+	stargazer_count INTEGER NOT NULL,
+	recorded_at DATETIME NOT NULL
+  );
+  CREATE INDEX IF NOT EXISTS star_snapshots_repo_id ON star_snapshots (repo_id, recorded_at);`
+
+const createCache string = `
+  CREATE TABLE IF NOT EXISTS cache (
+	key TEXT NOT NULL PRIMARY KEY,
+	payload BLOB NOT NULL,
+	fetched_at DATETIME NOT NULL
+  );`
+
+// migrations are run in order every time the database is opened so must be idempotent
+var migrations = []string{
+	create,
+	createTags,
+	createRepositoryTags,
+	createRepositoryNotes,
+	createStarSnapshots,
+}
+
+var cacheMigrations = []string{
+	createCache,
+}
+
+// Setup opens the database at path and the cache database at cachePath
+// creating any missing tables
+func Setup(path string, cachePath string) (*Database, error) {
+	db, err := open(path, migrations)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := db.Exec(create); err != nil {
+	cacheDB, err := open(cachePath, cacheMigrations)
+	if err != nil {
+		db.Close()
 		return nil, err
 	}
-	return &Database{db}, nil
+	return &Database{sqlDB: db, cacheDB: cacheDB}, nil
+}
+
+func open(path string, migrations []string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Close closes the underlying database connections
+func (db *Database) Close() error {
+	if err := db.cacheDB.Close(); err != nil {
+		db.sqlDB.Close()
+		return err
+	}
+	return db.sqlDB.Close()
 }
 
 // Insert a new repository into the database.
@@ -54,13 +133,14 @@ func (db *Database) Insert(repo *domain.Repository) (int64, error) {
 	return id, nil
 }
 
-// Delete removes a repository with the matching repo ID from the database.
+// Delete removes a repository with the matching repo ID from the database. Its note is
+// kept so that it is shown again if the repository is favourited again.
 func (db *Database) DeleteByRepoID(id string) error {
 	_, err := db.sqlDB.Exec("DELETE FROM gazed_repositories WHERE repo_id = ?;", id)
 	if err != nil {
 		return err
 	}
-	return nil
+	return db.SetTags(id, []string{})
 }
 
 func (db *Database) GetFavouriteByRepoID(id string) (*domain.FavouriteRepository, error) {
@@ -80,6 +160,44 @@ func (db *Database) GetFavouriteByRepoID(id string) (*domain.FavouriteRepository
 	return repo, nil
 }
 
+// GetFavouriteByName returns the favourite with the matching owner and name (case insensitive)
+func (db *Database) GetFavouriteByName(owner, name string) (*domain.FavouriteRepository, error) {
+	row := db.sqlDB.QueryRow(
+		"SELECT * FROM gazed_repositories WHERE owner = ? COLLATE NOCASE AND name = ? COLLATE NOCASE;",
+		owner,
+		name,
+	)
+	repo := &domain.FavouriteRepository{}
+	if err := row.Scan(
+		&repo.ID,
+		&repo.RepoID,
+		&repo.Name,
+		&repo.Owner,
+		&repo.Description,
+	); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// UpdateFavourite updates the stored name, owner and description of a favourite
+// e.g. after the repository has been renamed or transferred
+func (db *Database) UpdateFavourite(repo *domain.Repository) error {
+	if repo == nil {
+		return errors.New("could not update repository as it is missing!")
+	}
+	_, err := db.sqlDB.Exec(
+		"UPDATE gazed_repositories SET name = ?, owner = ?, description = ? WHERE repo_id = ?;",
+		repo.Name,
+		repo.Owner.Login,
+		repo.Description,
+		repo.ID,
+	)
+	return err
+}
+
 // ListFavourites pulls the repositories out of the gazers table and returns them as a list
 func (db *Database) ListFavourites() ([]*domain.FavouriteRepository, error) {
 	rows, err := db.sqlDB.Query("SELECT * FROM gazed_repositories;")
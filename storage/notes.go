@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"akinsho/gitgazer/domain"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// SetNote saves the note for the repository replacing any previous note,
+// an empty note removes it.
+func (db *Database) SetNote(repoID string, body string) error {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		_, err := db.sqlDB.Exec("DELETE FROM repository_notes WHERE repo_id = ?;", repoID)
+		return err
+	}
+	_, err := db.sqlDB.Exec(
+		"INSERT OR REPLACE INTO repository_notes (repo_id, body, updated_at) VALUES (?, ?, ?);",
+		repoID,
+		body,
+		time.Now().UTC(),
+	)
+	return err
+}
+
+// GetNoteByRepoID returns the note for the repository or nil if there isn't one
+func (db *Database) GetNoteByRepoID(repoID string) (*domain.Note, error) {
+	row := db.sqlDB.QueryRow(
+		"SELECT repo_id, body, updated_at FROM repository_notes WHERE repo_id = ?;",
+		repoID,
+	)
+	note := &domain.Note{}
+	if err := row.Scan(&note.RepoID, &note.Body, &note.UpdatedAt); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
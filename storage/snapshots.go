@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"akinsho/gitgazer/domain"
+	"time"
+)
+
+// InsertStarSnapshot records the repository's star count at the given time
+func (db *Database) InsertStarSnapshot(repoID string, count int, recordedAt time.Time) error {
+	_, err := db.sqlDB.Exec(
+		"INSERT INTO star_snapshots (repo_id, stargazer_count, recorded_at) VALUES (?, ?, ?);",
+		repoID,
+		count,
+		recordedAt.UTC(),
+	)
+	return err
+}
+
+// ListStarSnapshots returns the repository's star counts recorded since the given time
+// ordered from oldest to newest
+func (db *Database) ListStarSnapshots(repoID string, since time.Time) ([]*domain.StarSnapshot, error) {
+	rows, err := db.sqlDB.Query(`
+		SELECT repo_id, stargazer_count, recorded_at FROM star_snapshots
+		WHERE repo_id = ? AND recorded_at >= ?
+		ORDER BY recorded_at;`,
+		repoID,
+		since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	snapshots := []*domain.StarSnapshot{}
+	for rows.Next() {
+		snapshot := &domain.StarSnapshot{}
+		if err := rows.Scan(
+			&snapshot.RepoID,
+			&snapshot.StargazerCount,
+			&snapshot.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
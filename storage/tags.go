@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"akinsho/gitgazer/domain"
+	"strings"
+)
+
+// SetTags replaces the tags assigned to the repository with the given names, creating any
+// tags that do not yet exist and removing those that are no longer assigned to anything.
+func (db *Database) SetTags(repoID string, names []string) error {
+	tx, err := db.sqlDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM repository_tags WHERE repo_id = ?;", repoID); err != nil {
+		return err
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?);", name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO repository_tags (repo_id, tag_id) SELECT ?, id FROM tags WHERE name = ?;",
+			repoID,
+			name,
+		); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(
+		"DELETE FROM tags WHERE id NOT IN (SELECT DISTINCT tag_id FROM repository_tags);",
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTags returns every tag in alphabetical order
+func (db *Database) ListTags() ([]*domain.Tag, error) {
+	rows, err := db.sqlDB.Query("SELECT id, name FROM tags ORDER BY name;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := []*domain.Tag{}
+	for rows.Next() {
+		tag := &domain.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListTagsByRepoID returns the tags assigned to the repository in alphabetical order
+func (db *Database) ListTagsByRepoID(repoID string) ([]*domain.Tag, error) {
+	rows, err := db.sqlDB.Query(`
+		SELECT tags.id, tags.name FROM tags
+		INNER JOIN repository_tags ON repository_tags.tag_id = tags.id
+		WHERE repository_tags.repo_id = ?
+		ORDER BY tags.name;`,
+		repoID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := []*domain.Tag{}
+	for rows.Next() {
+		tag := &domain.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListRepoIDsByTag returns the IDs of every repository assigned to the named tag
+func (db *Database) ListRepoIDsByTag(name string) ([]string, error) {
+	rows, err := db.sqlDB.Query(`
+		SELECT repository_tags.repo_id FROM repository_tags
+		INNER JOIN tags ON repository_tags.tag_id = tags.id
+		WHERE tags.name = ?;`,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
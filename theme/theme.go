@@ -0,0 +1,182 @@
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+// Theme is the colour of each semantic element of the TUI
+type Theme struct {
+	Background             tcell.Color
+	ContrastBackground     tcell.Color
+	MoreContrastBackground tcell.Color
+	Border                 tcell.Color
+	Title                  tcell.Color
+	Text                   tcell.Color
+	SecondaryText          tcell.Color
+	ListText               tcell.Color
+	SelectedText           tcell.Color
+	SelectedBackground     tcell.Color
+	// Prompt is the colour of the labels of inputs
+	Prompt tcell.Color
+	// Key is the colour of the names of a repository's details e.g. Stars
+	Key    tcell.Color
+	Link   tcell.Color
+	Note   tcell.Color
+	Open   tcell.Color
+	Closed tcell.Color
+	// Muted is used for placeholder text e.g. when a list is empty
+	Muted     tcell.Color
+	LabelText tcell.Color
+	// Markdown is the glamour style used to render issue and pull request bodies,
+	// either the name of a built in style or the path to a JSON style
+	Markdown string
+}
+
+var presets = map[string]Theme{
+	Dark: {
+		Background:             tcell.ColorBlack,
+		ContrastBackground:     tcell.ColorDimGray,
+		MoreContrastBackground: tcell.ColorRebeccaPurple,
+		Border:                 tcell.ColorWhite,
+		Title:                  tcell.ColorBlue,
+		Text:                   tcell.ColorWhite,
+		SecondaryText:          tcell.ColorDarkGrey,
+		ListText:               tcell.ColorForestGreen,
+		SelectedText:           tcell.ColorWhite,
+		SelectedBackground:     tcell.ColorRebeccaPurple,
+		Prompt:                 tcell.ColorYellow,
+		Key:                    tcell.ColorRed,
+		Link:                   tcell.ColorBlue,
+		Note:                   tcell.ColorYellow,
+		Open:                   tcell.ColorGreen,
+		Closed:                 tcell.ColorRed,
+		Muted:                  tcell.ColorDarkGrey,
+		LabelText:              tcell.ColorBlack,
+		Markdown:               "dark",
+	},
+	Light: {
+		Background:             tcell.ColorWhite,
+		ContrastBackground:     tcell.ColorLightGray,
+		MoreContrastBackground: tcell.ColorRebeccaPurple,
+		Border:                 tcell.ColorBlack,
+		Title:                  tcell.ColorNavy,
+		Text:                   tcell.ColorBlack,
+		SecondaryText:          tcell.ColorGray,
+		ListText:               tcell.ColorDarkGreen,
+		SelectedText:           tcell.ColorWhite,
+		SelectedBackground:     tcell.ColorRebeccaPurple,
+		Prompt:                 tcell.ColorDarkBlue,
+		Key:                    tcell.ColorDarkRed,
+		Link:                   tcell.ColorBlue,
+		Note:                   tcell.ColorDarkGoldenrod,
+		Open:                   tcell.ColorDarkGreen,
+		Closed:                 tcell.ColorDarkRed,
+		Muted:                  tcell.ColorGray,
+		LabelText:              tcell.ColorBlack,
+		Markdown:               "light",
+	},
+	HighContrast: {
+		Background:             tcell.ColorBlack,
+		ContrastBackground:     tcell.ColorWhite,
+		MoreContrastBackground: tcell.ColorYellow,
+		Border:                 tcell.ColorWhite,
+		Title:                  tcell.ColorYellow,
+		Text:                   tcell.ColorWhite,
+		SecondaryText:          tcell.ColorWhite,
+		ListText:               tcell.ColorWhite,
+		SelectedText:           tcell.ColorBlack,
+		SelectedBackground:     tcell.ColorYellow,
+		Prompt:                 tcell.ColorYellow,
+		Key:                    tcell.ColorYellow,
+		Link:                   tcell.ColorAqua,
+		Note:                   tcell.ColorYellow,
+		Open:                   tcell.ColorLime,
+		Closed:                 tcell.ColorRed,
+		Muted:                  tcell.ColorSilver,
+		LabelText:              tcell.ColorBlack,
+		Markdown:               "dark",
+	},
+}
+
+// colors returns the theme's colours by the name used to override them in the config
+func (t *Theme) colors() map[string]*tcell.Color {
+	return map[string]*tcell.Color{
+		"background":               &t.Background,
+		"contrast_background":      &t.ContrastBackground,
+		"more_contrast_background": &t.MoreContrastBackground,
+		"border":                   &t.Border,
+		"title":                    &t.Title,
+		"text":                     &t.Text,
+		"secondary_text":           &t.SecondaryText,
+		"list_text":                &t.ListText,
+		"selected_text":            &t.SelectedText,
+		"selected_background":      &t.SelectedBackground,
+		"prompt":                   &t.Prompt,
+		"key":                      &t.Key,
+		"link":                     &t.Link,
+		"note":                     &t.Note,
+		"open":                     &t.Open,
+		"closed":                   &t.Closed,
+		"muted":                    &t.Muted,
+		"label_text":               &t.LabelText,
+	}
+}
+
+// New creates the theme from the named preset replacing any of its colours named in overrides,
+// colours are either a W3C colour name e.g. "rebeccapurple" or a hex code e.g. "#663399".
+// An empty markdown uses the preset's glamour style.
+func New(preset string, overrides map[string]string, markdown string) (*Theme, error) {
+	if preset == "" {
+		preset = Dark
+	}
+	base, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("theme: unknown preset %q, expected one of %s", preset, strings.Join(Presets(), ", "))
+	}
+	t := &base
+	colors := t.colors()
+	for name, value := range overrides {
+		color, ok := colors[name]
+		if !ok {
+			return nil, fmt.Errorf("theme: unknown colour %q", name)
+		}
+		normalized := strings.ToLower(strings.TrimSpace(value))
+		parsed := tcell.GetColor(normalized)
+		if parsed == tcell.ColorDefault && normalized != "default" {
+			return nil, fmt.Errorf("theme: %s: unknown colour %q", name, value)
+		}
+		*color = parsed
+	}
+	if markdown != "" {
+		t.Markdown = markdown
+	}
+	return t, nil
+}
+
+// Presets returns the names of the built in themes
+func Presets() []string {
+	names := []string{}
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tag returns the colour as used in a tview colour tag e.g. "[#ff0000]"
+func Tag(color tcell.Color) string {
+	if color == tcell.ColorDefault {
+		return "-"
+	}
+	return fmt.Sprintf("#%06x", color.Hex())
+}
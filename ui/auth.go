@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"akinsho/gitgazer/app"
+
+	"github.com/rivo/tview"
+)
+
+const loginPage = "login"
+
+// openLoginModal asks the user to log in again after github rejects the access token,
+// the TUI is suspended while they log in so that the OAuth or token prompt can use the terminal
+func openLoginModal(ctx *app.Context) {
+	if view.pages.HasPage(loginPage) {
+		return
+	}
+	current := UI.GetFocus()
+	modal := tview.NewModal().
+		SetText("Github rejected your access token, it may have been revoked.\nWould you like to log in again?").
+		AddButtons([]string{"Log in", "Quit"}).
+		SetDoneFunc(func(_ int, label string) {
+			view.pages.RemovePage(loginPage)
+			if label != "Log in" {
+				UI.Stop()
+				return
+			}
+			var err error
+			UI.Suspend(func() { err = ctx.Config.Login() })
+			if err != nil {
+				openErrorModal(err)
+				return
+			}
+			ctx.Client.SetToken(ctx.Config.Token)
+			UI.SetFocus(current)
+			refreshWidget(view.ActiveList())
+		})
+	view.pages.AddPage(loginPage, modal, true, true)
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"akinsho/gitgazer/app"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+// watchConfig reloads the config whenever the config file changes until the returned
+// function is called
+func watchConfig(ctx *app.Context) (stop func()) {
+	return app.WatchFile(ctx.Config.Path(), configPollInterval, func() {
+		UI.QueueUpdateDraw(reloadConfig)
+	})
+}
+
+// reloadConfig reads the config file again and rebuilds the layout so that changes to the
+// theme, keymap, layout and refresh interval are applied. The lists are redrawn from the
+// repositories already loaded keeping the current page, tabs and selection. If the config is
+// invalid the error is shown in the status bar and the current config is kept.
+func reloadConfig() {
+	ctx := view.context
+	config, err := ctx.Config.Reload()
+	if err != nil {
+		notify(app.ErrorLevel, fmt.Sprintf("Failed to reload config: %s", err))
+		return
+	}
+	// the config is not replaced in place as the previous layout's goroutines may still be
+	// reading it, they keep the context they were started with
+	state := view.state()
+	stopAutoRefresh()
+	setupLayout(ctx.WithConfig(config), state)
+	notify(app.InfoLevel, "Reloaded config")
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/github"
+	"akinsho/gitgazer/keymap"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const dashboardPage = "dashboard"
+
+// DashboardWidget shows the work involving the current user across all repositories
+type DashboardWidget struct {
+	component *tview.TreeView
+	context   *app.Context
+	work      *domain.Work
+}
+
+func (d *DashboardWidget) Context() *app.Context {
+	return d.context
+}
+
+func (d *DashboardWidget) Component() tview.Primitive {
+	var c interface{} = d.component
+	t, ok := c.(tview.Primitive)
+	if !ok {
+		panic("failed to cast to tview.Primitive")
+	}
+	return t
+}
+
+func (d *DashboardWidget) IsEmpty() bool {
+	if d.work == nil {
+		return true
+	}
+	return len(d.work.Authored)+len(d.work.ReviewRequested)+len(d.work.Assigned) == 0
+}
+
+// Open opens the currently selected pull request or issue in the browser
+func (d *DashboardWidget) Open() error {
+	node := d.component.GetCurrentNode()
+	if node == nil {
+		return nil
+	}
+	item, ok := node.GetReference().(*domain.WorkItem)
+	if !ok {
+		return nil
+	}
+	return common.OpenURL(item.URL)
+}
+
+// Refresh fetches the user's work from github and redraws the tree, this is blocking
+// so should be called from a goroutine
+func (d *DashboardWidget) Refresh() error {
+	root := d.component.GetRoot()
+	UI.QueueUpdateDraw(func() {
+		root.ClearChildren()
+		root.AddChild(tview.NewTreeNode("Loading...").SetSelectable(false))
+	})
+	work, err := github.ListViewerWork(d.context.Client)
+	if err != nil {
+		UI.QueueUpdateDraw(func() { root.ClearChildren() })
+		return err
+	}
+	UI.QueueUpdateDraw(func() {
+		d.work = work
+		root.ClearChildren()
+		root.AddChild(workSectionNode("Pull requests authored by me", work.Authored))
+		root.AddChild(workSectionNode("Pull requests awaiting my review", work.ReviewRequested))
+		root.AddChild(workSectionNode("Issues assigned to me", work.Assigned))
+		d.component.SetCurrentNode(root.GetChildren()[0])
+	})
+	return nil
+}
+
+func workSectionNode(title string, items []*domain.WorkItem) *tview.TreeNode {
+	section := tview.NewTreeNode(fmt.Sprintf("%s (%d)", title, len(items))).
+		SetColor(palette.Title)
+	if len(items) == 0 {
+		section.AddChild(tview.NewTreeNode("Nothing to see here").
+			SetColor(palette.Muted).
+			SetSelectable(false))
+		return section
+	}
+	for _, group := range github.GroupWorkByRepository(items) {
+		repo := tview.NewTreeNode(fmt.Sprintf("%s %s", repoIcon, group.Name)).
+			SetColor(palette.ListText)
+		for _, item := range group.Items {
+			repo.AddChild(tview.NewTreeNode(fmt.Sprintf("#%d %s", item.Number, item.Title)).
+				SetReference(item))
+		}
+		section.AddChild(repo)
+	}
+	return section
+}
+
+// onWorkItemSelected opens work items in the browser and expands or collapses
+// any other node in the tree
+func (d *DashboardWidget) onWorkItemSelected(node *tview.TreeNode) {
+	if _, ok := node.GetReference().(*domain.WorkItem); ok {
+		if err := d.Open(); err != nil {
+			showError(err)
+		}
+		return
+	}
+	node.SetExpanded(!node.IsExpanded())
+}
+
+func dashboardWidget(ctx *app.Context) *DashboardWidget {
+	widget := &DashboardWidget{context: ctx}
+	root := tview.NewTreeNode("My work").SetColor(palette.Title).SetSelectable(false)
+	tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root).SetTopLevel(1)
+	tree.SetSelectedFunc(widget.onWorkItemSelected)
+	tree.SetBorder(true).
+		SetTitle(common.Pad("My work", 1)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		keys := ctx.Config.Keymap
+		switch {
+		case keys.Is(keymap.Down, event):
+			return tcell.NewEventKey(tcell.KeyDown, 'j', tcell.ModNone)
+		case keys.Is(keymap.Up, event):
+			return tcell.NewEventKey(tcell.KeyUp, 'k', tcell.ModNone)
+		case keys.Is(keymap.Open, event):
+			if err := widget.Open(); err != nil {
+				showError(err)
+			}
+			return nil
+		}
+		return event
+	})
+	widget.component = tree
+	return widget
+}
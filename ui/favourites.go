@@ -3,20 +3,33 @@ package ui
 import (
 	"akinsho/gitgazer/app"
 	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/domain"
 	"akinsho/gitgazer/github"
+	"akinsho/gitgazer/keymap"
 	"fmt"
+	"strings"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type FavouritesWidget struct {
 	component *tview.List
 	context   *app.Context
+	// group is the name of the tag the favourites are currently filtered by
+	group string
+	// order is the order the favourites are shown in
+	order github.SortOrder
+	// visible are the favourites currently shown in the list in the order they are shown
+	visible []*domain.Repository
+	// rendering is true whilst the list is being updated in the background so that changes
+	// to the current item are not mistaken for the user moving through the list
+	rendering bool
 }
 
 func (f *FavouritesWidget) Open() error {
 	url := f.Context().State.Selected.URL
-	f.context.Logger.Write(fmt.Sprintf("Opening %s", url))
+	f.context.Logger.Info(fmt.Sprintf("Opening %s", url))
 	err := common.OpenURL(url)
 	if err != nil {
 		return err
@@ -29,42 +42,122 @@ func (f *FavouritesWidget) Context() *app.Context {
 }
 
 func (f *FavouritesWidget) OnChanged(index int, main, _ string, _ rune) {
-	repo, err := f.context.GetFavourite(index)
-	if err != nil {
-		openErrorModal(err)
+	if f.rendering {
 		return
 	}
+	repo := f.getVisible(index)
 	if repo == nil {
 		return
 	}
 	updateRepositoryList(f.context, repo)
 }
 
+func (f *FavouritesWidget) getVisible(index int) *domain.Repository {
+	if index < 0 || index >= len(f.visible) {
+		return nil
+	}
+	return f.visible[index]
+}
+
+// Filter returns the name of the group the favourites are currently filtered by
+func (f *FavouritesWidget) Filter() string {
+	return f.group
+}
+
+func (f *FavouritesWidget) IndexOf(id string) int {
+	for i, repo := range f.visible {
+		if repo.GetID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
 func (f *FavouritesWidget) SetSelected(i int) {
 	f.component.SetCurrentItem(i)
 }
 
-// refreshFavouritesList fetches all saved repositories from the database and
-// adds them to the View.favourites list.
+// Refresh draws the favourites into the list loading them first if they have not been
+// loaded yet. This is blocking so should be called from a goroutine.
 func (f *FavouritesWidget) Refresh() (err error) {
 	// FIXME: this happens twice on startup rather than once which causes weird intermittent
 	// race conditions.
-	f.context.Logger.Write("refreshing favourites list")
-	f.component.Clear()
+	f.context.Logger.Debug("refreshing favourites list")
 	favourites := f.context.State.Favourites
 	if len(favourites) == 0 {
-		f.component.AddItem("Loading favourites...", "", 0, nil)
-		UI.Draw()
-		favourites, err = github.RetrieveFavouriteRepositories(f.context)
+		favourites, err = f.load()
 		if err != nil {
 			return err
 		}
+	}
+	UI.QueueUpdateDraw(func() {
 		f.context.SetFavourites(favourites)
+		if err := f.render(); err != nil {
+			showError(err)
+		}
+	})
+	return nil
+}
+
+// load fetches the favourites drawing each one as it arrives, the progress is shown in the
+// sidebar's title with a spinner until they have all loaded
+func (f *FavouritesWidget) load() ([]*domain.Repository, error) {
+	// loaded and total are only accessed on the UI goroutine
+	loaded, total := 0, -1
+	UI.QueueUpdateDraw(func() {
+		f.component.Clear()
+		f.component.AddItem("Loading favourites...", "", 0, nil)
+	})
+	stop := startSpinner(func(frame string) {
+		if total < 0 {
+			view.sidebar.SetStatus(frame + " loading")
+		} else {
+			view.sidebar.SetStatus(fmt.Sprintf("%s %d/%d loaded", frame, loaded, total))
+		}
+	})
+	favourites, err := github.LoadFavouriteRepositories(f.context, func(repos []*domain.Repository, count int) {
+		UI.QueueUpdateDraw(func() {
+			loaded, total = len(repos), count
+			if len(repos) == 0 {
+				return
+			}
+			// the favourites arrive out of order so the cursor follows the repository
+			// rather than its index, moving it is not treated as the user selecting it
+			current := f.getVisible(f.component.GetCurrentItem()).GetID()
+			f.rendering = true
+			defer func() { f.rendering = false }()
+			if err := f.renderRepositories(repos); err != nil {
+				f.context.Logger.Warn(fmt.Sprintf("failed to draw the favourites: %s", err))
+			}
+			if i := f.IndexOf(current); i >= 0 {
+				f.component.SetCurrentItem(i)
+			}
+		})
+	})
+	UI.QueueUpdateDraw(func() {
+		stop()
+		view.sidebar.SetStatus("")
+	})
+	return favourites, err
+}
+
+// render draws the favourites in the current group into the list
+func (f *FavouritesWidget) render() error {
+	return f.renderRepositories(f.context.State.Favourites)
+}
+
+// renderRepositories draws the repositories in the current group into the list
+func (f *FavouritesWidget) renderRepositories(repos []*domain.Repository) error {
+	favourites, err := github.FilterFavouritesByTag(f.context, repos, f.group)
+	if err != nil {
+		return err
 	}
+	favourites = github.SortRepositories(favourites, f.order)
 	f.component.Clear()
+	f.visible = []*domain.Repository{}
 	if len(favourites) == 0 {
 		f.component.AddItem("No favourites found", "", 0, nil)
-		return
+		return nil
 	}
 
 	favs := favourites
@@ -76,14 +169,153 @@ func (f *FavouritesWidget) Refresh() (err error) {
 		main, secondary, showSecondaryText, onSelect := repositoryEntry(repo)
 		f.component.AddItem(main, secondary, 0, onSelect).ShowSecondaryText(showSecondaryText)
 	}
-	f.context.Logger.Write(fmt.Sprintf("Favourites item count: %d", f.component.GetItemCount()))
-	return
+	f.visible = favs
+	f.context.Logger.Debug(fmt.Sprintf("Favourites item count: %d", f.component.GetItemCount()))
+	return nil
+}
+
+// update replaces the favourites with their latest versions keeping the cursor on the
+// repository that was highlighted beforehand
+// CycleSort shows the favourites in the next order keeping the cursor on the same repository
+func (f *FavouritesWidget) CycleSort() github.SortOrder {
+	f.order = f.order.Next()
+	if err := f.update(f.context.State.Favourites); err != nil {
+		showError(err)
+	}
+	return f.order
+}
+
+func (f *FavouritesWidget) update(repos []*domain.Repository) error {
+	current := f.getVisible(f.component.GetCurrentItem()).GetID()
+	f.rendering = true
+	defer func() { f.rendering = false }()
+	f.context.SetFavourites(repos)
+	if err := f.render(); err != nil {
+		return err
+	}
+	for i, repo := range f.visible {
+		if repo.GetID() == current {
+			f.component.SetCurrentItem(i)
+			break
+		}
+	}
+	return nil
+}
+
+// Reload fetches the favourites from github ignoring any cached copy then redraws the
+// list keeping the cursor on the same repository. This is blocking so should be called
+// from a goroutine.
+func (f *FavouritesWidget) Reload() error {
+	favourites, err := github.Sync(f.context)
+	if err != nil {
+		return err
+	}
+	UI.QueueUpdateDraw(func() {
+		if err := f.update(favourites); err != nil {
+			showError(err)
+		}
+	})
+	return nil
+}
+
+// cycleGroup switches the list to show the favourites in the next group, after the last
+// group all favourites are shown again
+func (f *FavouritesWidget) cycleGroup() {
+	groups, err := github.ListTags(f.context)
+	if err != nil {
+		showError(err)
+		return
+	}
+	groups = append([]string{""}, groups...)
+	next := 0
+	for i, group := range groups {
+		if group == f.group {
+			next = (i + 1) % len(groups)
+			break
+		}
+	}
+	f.group = groups[next]
+	if err := f.render(); err != nil {
+		showError(err)
+		return
+	}
+	view.sidebar.UpdateTitle()
+}
+
+// editTags opens a prompt to change the tags assigned to the highlighted favourite
+func (f *FavouritesWidget) editTags() {
+	repo := f.getVisible(f.component.GetCurrentItem())
+	if repo == nil {
+		return
+	}
+	tags, err := github.GetFavouriteTags(f.context, repo)
+	if err != nil {
+		showError(err)
+		return
+	}
+	title := fmt.Sprintf("Tags for %s (comma separated)", repo.GetName())
+	openPrompt(title, "Tags: ", strings.Join(tags, ", "), func(text string) {
+		if err := github.TagFavourite(f.context, repo, github.ParseTags(text)); err != nil {
+			showError(err)
+			return
+		}
+		if f.group != "" {
+			if err := f.render(); err != nil {
+				showError(err)
+				return
+			}
+			if len(f.visible) == 0 {
+				f.cycleGroup()
+			}
+		}
+	})
+}
+
+// editNote opens the note about the highlighted favourite in the user's editor, the TUI is
+// suspended until the editor exits. Saving an empty note deletes it.
+func (f *FavouritesWidget) editNote() {
+	repo := f.getVisible(f.component.GetCurrentItem())
+	if repo == nil {
+		return
+	}
+	note, err := github.GetNote(f.context, repo)
+	if err != nil {
+		showError(err)
+		return
+	}
+	var text string
+	UI.Suspend(func() { text, err = common.EditText(note.GetBody()) })
+	if err != nil {
+		showError(err)
+		return
+	}
+	if err := github.SaveNote(f.context, repo, text); err != nil {
+		showError(err)
+		return
+	}
+	setRepoDescription(f.context, repo)
+}
+
+func (f *FavouritesWidget) inputHandler(event *tcell.EventKey) *tcell.EventKey {
+	keys := f.context.Config.Keymap
+	switch {
+	case keys.Is(keymap.EditTags, event):
+		f.editTags()
+		return nil
+	case keys.Is(keymap.CycleGroup, event):
+		f.cycleGroup()
+		return nil
+	case keys.Is(keymap.EditNote, event):
+		f.editNote()
+		return nil
+	}
+	return event
 }
 
 func (f *FavouritesWidget) IsEmpty() bool {
 	favs, err := github.ListSavedFavourites(f.context)
 	if err != nil {
-		openErrorModal(err)
+		showError(err)
 		return true
 	}
 	if len(favs) > 0 {
@@ -107,6 +339,7 @@ func favouritesWidget(ctx *app.Context) *FavouritesWidget {
 		onSelected: func(int, string, string, rune) {},
 		onChanged:  widget.OnChanged,
 	})
+	favourites.SetInputCapture(widget.inputHandler)
 	widget.component = favourites
 	return widget
 }
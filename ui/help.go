@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/keymap"
+	"akinsho/gitgazer/theme"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const helpPage = "help"
+
+// contextTitles describe where the bindings of each context are active
+var contextTitles = map[keymap.Context]string{
+	keymap.Global:     "Global",
+	keymap.List:       "Lists",
+	keymap.Sidebar:    "Sidebar (starred and favourites)",
+	keymap.Favourites: "Favourites",
+}
+
+// detailsActions act on the details pane although their keys are pressed in the sidebar,
+// they are listed in a section of their own
+var detailsActions = map[keymap.Action]bool{
+	keymap.ScrollDown: true,
+	keymap.ScrollUp:   true,
+}
+
+// keymapText lists every action with its keys grouped by the context it is active in,
+// actions without any keys are shown as unbound
+func keymapText(keys *keymap.Keymap) string {
+	sections := []string{}
+	for _, context := range keymap.Contexts {
+		context := context
+		sections = append(sections, bindingsText(keys, contextTitles[context], func(binding keymap.Binding) bool {
+			return binding.Context == context && !detailsActions[binding.Action]
+		}))
+		if context == keymap.Sidebar {
+			sections = append(sections, bindingsText(keys, "Details (from the sidebar)", func(binding keymap.Binding) bool {
+				return detailsActions[binding.Action]
+			}))
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// bindingsText lists the bindings which are included under the title
+func bindingsText(keys *keymap.Keymap, title string, include func(keymap.Binding) bool) string {
+	lines := []string{fmt.Sprintf("[%s::b]%s[-::-]", theme.Tag(palette.Title), title)}
+	for _, binding := range keys.Bindings() {
+		if !include(binding) {
+			continue
+		}
+		label := keys.Label(binding.Action)
+		if label == "" {
+			label = "unbound"
+		}
+		lines = append(lines, fmt.Sprintf(
+			"  [%s]%-16s[-] %s",
+			theme.Tag(palette.Key),
+			tview.Escape(label),
+			binding.Description,
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleHelp shows or hides the list of keybindings over the whole screen
+func toggleHelp(layout *Layout) {
+	toggleOverlay(layout, helpPage, "Keybindings", func() string {
+		return keymapText(layout.keys())
+	})
+}
+
+// toggleOverlay shows or hides a scrollable page of text over the whole screen,
+// the text is generated each time the page is opened
+func toggleOverlay(layout *Layout, page, title string, text func() string) {
+	if layout.pages.HasPage(page) {
+		layout.pages.RemovePage(page)
+		if front, _ := layout.pages.GetFrontPage(); front == dashboardPage {
+			UI.SetFocus(layout.dashboard.Component())
+		} else {
+			UI.SetFocus(layout.ActiveList().Component())
+		}
+		return
+	}
+	keys := layout.keys()
+	overlay := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(text())
+	overlay.SetBorder(true).
+		SetTitle(common.Pad(fmt.Sprintf("%s (%s to close)", title, keys.Label(keymap.Close)), 1)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(1, 1, 2, 2)
+	overlay.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case keys.Is(keymap.Close, event):
+			toggleOverlay(layout, page, title, text)
+			return nil
+		case keys.Is(keymap.Down, event):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case keys.Is(keymap.Up, event):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+	layout.pages.AddPage(page, overlay, true, true)
+	UI.SetFocus(overlay)
+}
@@ -4,6 +4,7 @@ import (
 	"akinsho/gitgazer/app"
 	"akinsho/gitgazer/common"
 	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/theme"
 	"fmt"
 	"strings"
 
@@ -70,9 +71,9 @@ func (r *IssuesWidget) Refresh() (err error) {
 			if issue.Author != nil && issue.Author.Login != "" {
 				author += "[::bu]@" + issue.Author.Login + "[::-]"
 			}
-			issueColor := "green"
+			issueColor := theme.Tag(palette.Open)
 			if issue.Closed {
-				issueColor = "red"
+				issueColor = theme.Tag(palette.Closed)
 			}
 			body := convertToMarkdown(issue.Body)
 			lines = append(
@@ -122,7 +123,7 @@ func drawLabels(labels []*domain.Label) string {
 		color := "#" + strings.ToUpper(label.Color)
 		left := fmt.Sprintf("[%s]%s", color, leftPillIcon)
 		right := fmt.Sprintf("[%s:-:]%s", color, rightPillIcon)
-		name := fmt.Sprintf(`[black:%s]%s`, color, strings.ToUpper(label.Name))
+		name := fmt.Sprintf(`[%s:%s]%s`, theme.Tag(palette.LabelText), color, strings.ToUpper(label.Name))
 		renderedLabels = append(renderedLabels, left+name+right)
 	}
 	return strings.Join(renderedLabels, " ")
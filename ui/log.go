@@ -2,29 +2,46 @@ package ui
 
 import (
 	"fmt"
-	"time"
 
 	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/theme"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+// maxLogLines is the number of lines kept in the log panel
+const maxLogLines = 500
+
+// LogWidget shows the entries written to the context's logger when the log panel is enabled
 type LogWidget struct {
 	component *tview.TextView
 }
 
-func (d *LogWidget) Write(str string) {
-	previous := d.Read()
-	now := time.Now().Format("02-01-2006 15:04:05")
-	output := fmt.Sprintf("[-:-:b]%s[::-]: %s", now, str)
-	if previous != "" {
-		output = previous + output
+// levelColor returns the colour the level of an entry is shown in
+func levelColor(level app.LogLevel) tcell.Color {
+	switch level {
+	case app.ErrorLevel:
+		return palette.Closed
+	case app.WarnLevel:
+		return palette.Note
+	case app.DebugLevel:
+		return palette.Muted
+	default:
+		return palette.Open
 	}
-	d.component.SetText(output)
 }
 
-func (d *LogWidget) Read() string {
-	return d.component.GetText(false)
+// Write adds the entry to the end of the panel, it is safe to call from any goroutine
+func (d *LogWidget) Write(entry app.LogEntry) {
+	fmt.Fprintf(
+		d.component,
+		"[-:-:b]%s[::-] [%s]%-5s[-] %s\n",
+		entry.Time.Format("02-01-2006 15:04:05"),
+		theme.Tag(levelColor(entry.Level)),
+		entry.Level,
+		tview.Escape(entry.Message),
+	)
 }
 
 func (d *LogWidget) Refresh() (err error) {
@@ -41,6 +58,9 @@ func (d *LogWidget) IsEmpty() bool {
 
 func logWidget(_ *app.Context) *LogWidget {
 	debug := tview.NewTextView()
-	debug.SetDynamicColors(true).SetBorder(true).SetTitle("Debug")
+	debug.SetDynamicColors(true).
+		SetMaxLines(maxLogLines).
+		SetBorder(true).
+		SetTitle("Debug")
 	return &LogWidget{debug}
 }
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/github"
+	"akinsho/gitgazer/keymap"
+	"akinsho/gitgazer/theme"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const palettePage = "palette"
+
+// paletteCommand is an entry in the command palette
+type paletteCommand struct {
+	title string
+	keys  string
+	run   func()
+}
+
+// focusedContext returns the keymap context of the focused primitive
+func focusedContext(focus tview.Primitive) keymap.Context {
+	switch focus {
+	case view.favourites.component:
+		return keymap.Favourites
+	case view.repos.component:
+		return keymap.Sidebar
+	}
+	switch focus.(type) {
+	case *tview.List, *tview.TreeView:
+		return keymap.List
+	}
+	return keymap.Global
+}
+
+// paletteCommands returns the commands which can be run where the palette was opened, actions
+// from the keymap are run by sending their first key to the previously focused primitive
+func paletteCommands(ctx *app.Context, focus tview.Primitive) []paletteCommand {
+	keys := ctx.Config.Keymap
+	current := focusedContext(focus)
+	commands := []paletteCommand{}
+	for _, binding := range keys.Bindings() {
+		chords := keys.Chords(binding.Action)
+		if binding.Action == keymap.CommandPalette || len(chords) == 0 || !current.Includes(binding.Context) {
+			continue
+		}
+		event := chords[0].Event()
+		commands = append(commands, paletteCommand{
+			title: binding.Description,
+			keys:  keys.Label(binding.Action),
+			run:   func() { UI.QueueEvent(event) },
+		})
+	}
+	if focus == view.repos.component {
+		commands = append(commands, paletteCommand{
+			title: "Favourite or unfavourite the repository",
+			keys:  "Enter",
+			run: func() {
+				index := view.repos.component.GetCurrentItem()
+				onRepoSelect(ctx, index, "", "", 0)
+			},
+		})
+	}
+	commands = append(commands, paletteCommand{
+		title: "Export favourites",
+		run:   func() { openExportPrompt(ctx) },
+	})
+	return commands
+}
+
+// filterCommands returns the commands matching the query, best matches first
+func filterCommands(commands []paletteCommand, query string) []paletteCommand {
+	type match struct {
+		command paletteCommand
+		score   int
+	}
+	matches := []match{}
+	for _, command := range commands {
+		if score, ok := common.FuzzyMatch(query, command.title); ok {
+			matches = append(matches, match{command, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	filtered := []paletteCommand{}
+	for _, m := range matches {
+		filtered = append(filtered, m.command)
+	}
+	return filtered
+}
+
+// openCommandPalette lists the commands available where it was opened, typing narrows the
+// list down and selecting a command closes the palette and runs it
+func openCommandPalette(ctx *app.Context) {
+	if view.pages.HasPage(palettePage) {
+		return
+	}
+	focus := UI.GetFocus()
+	commands := paletteCommands(ctx, focus)
+	filtered := commands
+
+	dismiss := func() {
+		view.pages.RemovePage(palettePage)
+		UI.SetFocus(focus)
+	}
+
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetPlaceholder("Type a command").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	list := listWidget(ListOptions{})
+	list.ShowSecondaryText(false)
+
+	render := func() {
+		list.Clear()
+		for _, command := range filtered {
+			text := fmt.Sprintf("%-44s [%s]%s", command.title, theme.Tag(palette.Key), tview.Escape(command.keys))
+			list.AddItem(text, "", 0, nil)
+		}
+	}
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		if index < 0 || index >= len(filtered) {
+			return
+		}
+		dismiss()
+		filtered[index].run()
+	})
+	input.SetChangedFunc(func(text string) {
+		filtered = filterCommands(commands, text)
+		render()
+	})
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// letters are typed into the query so the list is moved using the tab keys
+		keys := ctx.Config.Keymap
+		switch {
+		case keys.Is(keymap.NextTab, event):
+			event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case keys.Is(keymap.PreviousTab, event):
+			event = tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		switch event.Key() {
+		case tcell.KeyDown, tcell.KeyUp, tcell.KeyEnter:
+			// the input keeps focus so the list is moved on its behalf
+			list.InputHandler()(event, func(tview.Primitive) {})
+			return nil
+		}
+		if keys.Is(keymap.Close, event) {
+			dismiss()
+			return nil
+		}
+		return event
+	})
+	render()
+
+	component := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	component.SetBorder(true).
+		SetTitle(common.Pad("Commands", 1)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+
+	view.pages.AddPage(palettePage, floatingWindow(component, 70, 20), true, true)
+	UI.SetFocus(input)
+}
+
+// openExportPrompt asks for the file to export the favourites to, the format is derived
+// from the file's extension
+func openExportPrompt(ctx *app.Context) {
+	openPrompt("Export favourites (.json, .yaml or .csv)", "Export to: ", "favourites.json", func(path string) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return
+		}
+		if err := exportFavourites(ctx, path); err != nil {
+			showError(err)
+			return
+		}
+		notify(app.InfoLevel, fmt.Sprintf("Exported favourites to %s", path))
+	})
+}
+
+func exportFavourites(ctx *app.Context, path string) error {
+	format, err := github.ExportFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := github.ExportFavourites(ctx, file, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
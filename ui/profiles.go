@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/keymap"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const profilesPage = "profiles"
+
+// stopAutoRefresh stops the auto refresh of the current context so it can be
+// restarted when the profile is switched
+var stopAutoRefresh = func() {}
+
+// openProfilePicker lists the configured profiles, selecting one switches to it
+func openProfilePicker(ctx *app.Context) {
+	if view.pages.HasPage(profilesPage) {
+		return
+	}
+	current := UI.GetFocus()
+	dismiss := func() {
+		view.pages.RemovePage(profilesPage)
+		UI.SetFocus(current)
+	}
+	list := listWidget(ListOptions{})
+	list.ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(common.Pad("Profiles", 1))
+	for i, name := range ctx.Config.Profiles() {
+		name := name
+		text := name
+		if name == ctx.Config.Profile {
+			text = fmt.Sprintf("%s (current)", name)
+			list.SetCurrentItem(i)
+		}
+		list.AddItem(text, "", 0, func() {
+			view.pages.RemovePage(profilesPage)
+			if name == ctx.Config.Profile {
+				UI.SetFocus(current)
+				return
+			}
+			switchProfile(ctx, name)
+		})
+	}
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		keys := ctx.Config.Keymap
+		switch {
+		case keys.Is(keymap.Down, event):
+			return tcell.NewEventKey(tcell.KeyDown, 'j', tcell.ModNone)
+		case keys.Is(keymap.Up, event):
+			return tcell.NewEventKey(tcell.KeyUp, 'k', tcell.ModNone)
+		case keys.Is(keymap.Close, event):
+			dismiss()
+			return nil
+		}
+		return event
+	})
+	view.pages.AddPage(profilesPage, floatingWindow(list, 40, len(ctx.Config.Profiles())+2), true, true)
+	UI.SetFocus(list)
+}
+
+// switchProfile rebuilds the context and layout for the named profile. The TUI is
+// suspended while authenticating in case the user needs to log in to the profile.
+func switchProfile(current *app.Context, name string) {
+	config, err := current.Config.ForProfile(name)
+	if err != nil {
+		showError(err)
+		return
+	}
+	var ctx *app.Context
+	UI.Suspend(func() { ctx, err = app.Open(config) })
+	if err != nil {
+		showError(err)
+		return
+	}
+	ctx.SetLogger(current.Logger)
+	stopAutoRefresh()
+	previous := view
+	setupLayout(ctx, nil)
+	notify(app.InfoLevel, fmt.Sprintf("Switched to the %s profile", name))
+	// the previous layout's goroutines may still be using the database and they can
+	// queue updates so they are waited for off the UI goroutine
+	go func() {
+		previous.tasks.Wait()
+		if err := current.DB.Close(); err != nil {
+			ctx.Logger.Error(fmt.Sprintf("failed to close the database of %s: %s", current.Config.Profile, err))
+		}
+	}()
+}
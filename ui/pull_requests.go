@@ -2,6 +2,7 @@ package ui
 
 import (
 	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/theme"
 	"fmt"
 	"strings"
 
@@ -44,9 +45,9 @@ func (p *PullRequestsWidget) Refresh() (err error) {
 	} else {
 		for _, pr := range pullRequests {
 			text := convertToMarkdown(pr.Body)
-			stateColor := "green"
+			stateColor := theme.Tag(palette.Open)
 			if pr.Closed {
-				stateColor = "red"
+				stateColor = theme.Tag(palette.Closed)
 			}
 			status := fmt.Sprintf(" [%s]", stateColor) + tview.Escape(fmt.Sprintf("[%s]", pr.State)) + "[-:-:-]"
 			author := ""
@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/github"
+
+	"github.com/rivo/tview"
+)
+
+// startAutoRefresh re-fetches the favourites and the selected repository every interval
+// until the returned function is called. An interval of zero disables auto refreshing.
+func startAutoRefresh(layout *Layout, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stop = func() { close(done) }
+	if interval <= 0 {
+		return stop
+	}
+	layout.background(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				autoRefresh(layout)
+			}
+		}
+	})
+	return stop
+}
+
+// autoRefresh fetches the latest favourites and selected repository in the background
+// then updates the widgets without moving the cursor. The favourites are fetched past the
+// cache, which could be older than the interval, and are not saved or snapshotted as that
+// is the job of the daemon. Nothing is updated if the layout has been replaced meanwhile.
+func autoRefresh(layout *Layout) {
+	ctx := layout.context
+	// the state is only accessed on the UI goroutine so a copy is taken before fetching
+	var loaded bool
+	var selected *domain.Repository
+	UI.QueueUpdate(func() {
+		loaded = len(ctx.State.Favourites) > 0
+		selected = ctx.State.Selected
+	})
+	var favourites []*domain.Repository
+	// favourites that have never been loaded are left for the widget to load when opened
+	if loaded {
+		repos, err := github.RefreshFavouriteRepositories(ctx)
+		if err != nil {
+			UI.QueueUpdateDraw(func() { notify(app.WarnLevel, fmt.Sprintf("Auto refresh failed: %s", err)) })
+			return
+		}
+		favourites = repos
+	}
+
+	updated := findRepository(favourites, selected.GetID())
+	if selected != nil && updated == nil {
+		repo, err := github.FetchRepository(ctx.Client, selected)
+		if err != nil {
+			UI.QueueUpdateDraw(func() { notify(app.WarnLevel, fmt.Sprintf("Auto refresh failed: %s", err)) })
+		} else {
+			updated = repo
+		}
+	}
+
+	UI.QueueUpdateDraw(func() {
+		if layout != view {
+			return
+		}
+		if favourites != nil {
+			if err := layout.favourites.update(favourites); err != nil {
+				showError(err)
+				return
+			}
+			layout.sidebar.UpdateTitle()
+		}
+		if updated != nil && ctx.State.Selected.GetID() == updated.GetID() {
+			ctx.SetSelected(updated)
+			setRepoDescription(ctx, updated)
+			refreshDetails()
+		}
+		ctx.Logger.Info("auto refreshed repositories")
+	})
+}
+
+// reloadSelected re-fetches the selected repository from github and redraws it wherever
+// it is shown whilst displaying a spinner in the description's title
+func reloadSelected(ctx *app.Context) {
+	selected := ctx.State.Selected
+	if selected == nil {
+		return
+	}
+	layout := view
+	stop := startSpinner(func(frame string) {
+		layout.description.SetTitle(common.Pad(frame+" "+selected.GetName(), 1))
+	})
+	layout.background(func() {
+		updated, err := github.RefreshRepository(ctx, selected)
+		UI.QueueUpdateDraw(func() {
+			stop()
+			if layout != view {
+				return
+			}
+			if err != nil {
+				setRepoDescription(ctx, ctx.State.Selected)
+				showError(err)
+				return
+			}
+			replaceRepository(ctx.State.Favourites, updated)
+			replaceRepository(ctx.State.Starred, updated)
+			if len(ctx.State.Favourites) > 0 {
+				if err := layout.favourites.update(ctx.State.Favourites); err != nil {
+					showError(err)
+				}
+			}
+			if ctx.State.Selected.GetID() == updated.GetID() {
+				ctx.SetSelected(updated)
+				refreshDetails()
+			}
+			setRepoDescription(ctx, ctx.State.Selected)
+		})
+	})
+}
+
+// reloadList re-fetches every repository in the list from github whilst displaying
+// a spinner in the panel's title
+func reloadList(panel *TabbedPanelWidget, list ListWidget) {
+	reloadable, ok := list.(ReloadableWidget)
+	if !ok {
+		return
+	}
+	stop := startSpinner(func(frame string) {
+		panel.component.SetTitle(common.Pad(frame+" "+getPanelTitle(panel.entries, panel.entries[panel.currentPanel]), 1))
+	})
+	layout := view
+	layout.background(func() {
+		err := reloadable.Reload()
+		UI.QueueUpdateDraw(func() {
+			stop()
+			if layout != view {
+				return
+			}
+			panel.UpdateTitle()
+			if err != nil {
+				showError(err)
+			}
+		})
+	})
+}
+
+// replaceRepository swaps the repository with a matching ID for the updated version
+func replaceRepository(repos []*domain.Repository, updated *domain.Repository) {
+	for i, repo := range repos {
+		if repo.GetID() == updated.GetID() {
+			repos[i] = updated
+		}
+	}
+}
+
+// refreshDetails redraws the details of the selected repository keeping the scroll position
+func refreshDetails() {
+	details := view.ActiveDetails()
+	text, ok := details.Component().(*tview.TextView)
+	if !ok {
+		if err := details.Refresh(); err != nil {
+			showError(err)
+		}
+		return
+	}
+	row, column := text.GetScrollOffset()
+	if err := details.Refresh(); err != nil {
+		showError(err)
+		return
+	}
+	text.ScrollTo(row, column)
+}
+
+func findRepository(repos []*domain.Repository, id string) *domain.Repository {
+	for _, repo := range repos {
+		if repo.GetID() == id {
+			return repo
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/github"
+	"akinsho/gitgazer/keymap"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const searchPage = "search"
+
+// SearchWidget allows the user to search github for repositories and favourite
+// any of the results regardless of whether or not they have starred them
+type SearchWidget struct {
+	component *tview.Flex
+	input     *tview.InputField
+	results   *tview.List
+	context   *app.Context
+	repos     []*domain.Repository
+}
+
+func (s *SearchWidget) Context() *app.Context {
+	return s.context
+}
+
+func (s *SearchWidget) Component() tview.Primitive {
+	var c interface{} = s.component
+	t, ok := c.(tview.Primitive)
+	if !ok {
+		panic("failed to cast to tview.Primitive")
+	}
+	return t
+}
+
+func (s *SearchWidget) IsEmpty() bool {
+	return len(s.repos) == 0
+}
+
+func (s *SearchWidget) SetSelected(i int) {
+	s.results.SetCurrentItem(i)
+}
+
+func (s *SearchWidget) selected() *domain.Repository {
+	index := s.results.GetCurrentItem()
+	if index < 0 || index >= len(s.repos) {
+		return nil
+	}
+	return s.repos[index]
+}
+
+// Open opens the currently highlighted search result in the browser
+func (s *SearchWidget) Open() error {
+	repo := s.selected()
+	if repo == nil {
+		return nil
+	}
+	return common.OpenURL(repo.URL)
+}
+
+// Refresh searches github using the text in the input field, this is blocking
+// so should be called from a goroutine
+func (s *SearchWidget) Refresh() error {
+	query := strings.TrimSpace(s.input.GetText())
+	if query == "" {
+		return nil
+	}
+	UI.QueueUpdateDraw(func() {
+		// the previous results are forgotten so selecting the placeholder does nothing
+		s.repos = nil
+		s.results.Clear()
+		s.results.AddItem(fmt.Sprintf("Searching for %q...", query), "", 0, nil)
+	})
+	repos, err := github.SearchRepositories(s.context.Client, query)
+	if err != nil {
+		UI.QueueUpdateDraw(func() { s.results.Clear() })
+		return err
+	}
+	favourites := make([]bool, len(repos))
+	for i, repo := range repos {
+		favourites[i] = isFavourite(s.context, repo)
+	}
+	UI.QueueUpdateDraw(func() {
+		s.repos = repos
+		s.results.Clear()
+		if len(repos) == 0 {
+			s.results.AddItem("No repositories found", "", 0, nil)
+			return
+		}
+		for i, repo := range repos {
+			main, secondary := searchResultEntry(repo, favourites[i])
+			s.results.AddItem(main, secondary, 0, nil)
+		}
+		UI.SetFocus(s.results)
+	})
+	return nil
+}
+
+func searchResultEntry(repo *domain.Repository, favourite bool) (string, string) {
+	name := fmt.Sprintf("%s %s/%s [::d]🌟%d[::-]",
+		repoIcon,
+		repo.Owner.Login,
+		repo.GetName(),
+		repo.GetStargazerCount(),
+	)
+	if favourite {
+		name = fmt.Sprintf("%s [hotpink]%s", name, heartIcon)
+	}
+	return name, repo.GetDescription()
+}
+
+// onResultSelected favourites the highlighted repository
+func (s *SearchWidget) onResultSelected(index int, _, _ string, _ rune) {
+	repo := s.selected()
+	if repo == nil {
+		return
+	}
+	if isFavourite(s.context, repo) {
+		return
+	}
+	if err := github.FavouriteRepository(s.context, repo); err != nil {
+		showError(err)
+		return
+	}
+	main, secondary := searchResultEntry(repo, true)
+	s.results.SetItemText(index, main, secondary)
+	notify(app.InfoLevel, fmt.Sprintf("Added %s to favourites", repo.GetName()))
+}
+
+func (s *SearchWidget) search() {
+	view.background(func() {
+		if err := s.Refresh(); err != nil {
+			UI.QueueUpdateDraw(func() { showError(err) })
+		}
+	})
+}
+
+func (s *SearchWidget) onInputDone(key tcell.Key) {
+	switch key {
+	case tcell.KeyEnter:
+		s.search()
+	case tcell.KeyEscape:
+		toggleSearch(view)
+	}
+}
+
+func (s *SearchWidget) queryInputHandler(event *tcell.EventKey) *tcell.EventKey {
+	keys := s.context.Config.Keymap
+	if keys.Is(keymap.NextSection, event) || keys.Is(keymap.PreviousSection, event) {
+		UI.SetFocus(s.results)
+		return nil
+	}
+	return event
+}
+
+// handlesKey reports whether the search window handles the key itself rather than the
+// global bindings i.e. moving between the input and the results
+func (s *SearchWidget) handlesKey(event *tcell.EventKey) bool {
+	keys := s.context.Config.Keymap
+	if keys.Is(keymap.NextSection, event) || keys.Is(keymap.PreviousSection, event) {
+		return true
+	}
+	return s.results.HasFocus() && keys.Is(keymap.Search, event)
+}
+
+func (s *SearchWidget) resultsInputHandler(event *tcell.EventKey) *tcell.EventKey {
+	keys := s.context.Config.Keymap
+	switch {
+	case keys.Is(keymap.Down, event):
+		return tcell.NewEventKey(tcell.KeyDown, 'j', tcell.ModNone)
+	case keys.Is(keymap.Up, event):
+		return tcell.NewEventKey(tcell.KeyUp, 'k', tcell.ModNone)
+	case keys.Is(keymap.Search, event),
+		keys.Is(keymap.NextSection, event),
+		keys.Is(keymap.PreviousSection, event):
+		UI.SetFocus(s.input)
+		return nil
+	case keys.Is(keymap.Close, event):
+		toggleSearch(view)
+		return nil
+	case keys.Is(keymap.Open, event):
+		if err := s.Open(); err != nil {
+			showError(err)
+		}
+		return nil
+	}
+	return event
+}
+
+func searchWidget(ctx *app.Context) *SearchWidget {
+	widget := &SearchWidget{context: ctx}
+	input := tview.NewInputField().
+		SetLabel("Search: ").
+		SetPlaceholder("e.g. language:go stars:>1000 tui").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetDoneFunc(widget.onInputDone)
+	input.SetInputCapture(widget.queryInputHandler)
+	results := listWidget(ListOptions{
+		onSelected: widget.onResultSelected,
+		onChanged:  func(int, string, string, rune) {},
+	})
+	results.SetInputCapture(widget.resultsInputHandler)
+
+	component := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(results, 0, 1, false)
+	component.SetBorder(true).
+		SetTitle(common.Pad("Search repositories (<Enter> to favourite, <Esc> to close)", 1)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+
+	widget.component = component
+	widget.input = input
+	widget.results = results
+	return widget
+}
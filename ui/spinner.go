@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// startSpinner calls render with the next frame of the spinner animation on the UI goroutine
+// until the returned function is called. The returned function must also be called
+// on the UI goroutine e.g. inside QueueUpdateDraw.
+func startSpinner(render func(frame string)) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame = (frame + 1) % len(spinnerFrames) {
+			current := spinnerFrames[frame]
+			UI.QueueUpdateDraw(func() {
+				select {
+				case <-done:
+				default:
+					render(current)
+				}
+			})
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
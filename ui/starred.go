@@ -16,6 +16,13 @@ var heartIcon = "❤"
 type StarredWidget struct {
 	component *tview.List
 	context   *app.Context
+	// order is the order the starred repositories are shown in
+	order github.SortOrder
+	// visible are the repositories currently shown in the list in the order they are shown
+	visible []*domain.Repository
+	// rendering is true whilst the list is being updated in the background so that changes
+	// to the current item are not mistaken for the user moving through the list
+	rendering bool
 }
 
 func (s *StarredWidget) Open() error {
@@ -50,23 +57,73 @@ func (r *StarredWidget) removeFavouriteIndicator(i int, repo *domain.Repository)
 	r.component.SetItemText(i, main, secondary)
 }
 
+func (r *StarredWidget) IndexOf(id string) int {
+	for i, repo := range r.visible {
+		if repo.GetID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *StarredWidget) getVisible(index int) *domain.Repository {
+	if index < 0 || index >= len(r.visible) {
+		return nil
+	}
+	return r.visible[index]
+}
+
+// CycleSort shows the starred repositories in the next order keeping the cursor on the
+// same repository
+func (r *StarredWidget) CycleSort() github.SortOrder {
+	current := r.getVisible(r.component.GetCurrentItem()).GetID()
+	r.order = r.order.Next()
+	r.rendering = true
+	defer func() { r.rendering = false }()
+	r.render()
+	if i := r.IndexOf(current); i >= 0 {
+		r.component.SetCurrentItem(i)
+	}
+	return r.order
+}
+
 func (r *StarredWidget) SetSelected(i int) {
 	r.component.SetCurrentItem(i)
 }
 
+// Refresh draws the starred repositories into the list loading them first if they have not
+// been loaded yet. This is blocking so should be called from a goroutine.
 func (r *StarredWidget) Refresh() (err error) {
-	r.component.Clear()
 	starred := r.context.State.Starred
 	if len(starred) == 0 {
-		r.component.AddItem("Loading repositories...", "", 0, nil)
-		UI.Draw()
-		starred, err = github.ListStarredRepositories(r.context.Client)
+		UI.QueueUpdateDraw(func() {
+			r.component.Clear()
+			r.component.AddItem("Loading repositories...", "", 0, nil)
+		})
+		stop := startSpinner(func(frame string) {
+			view.sidebar.SetStatus(frame + " loading")
+		})
+		starred, err = github.LoadStarredRepositories(r.context)
+		UI.QueueUpdateDraw(func() {
+			stop()
+			view.sidebar.SetStatus("")
+		})
 		if err != nil {
 			return err
 		}
-		r.context.SetStarred(starred)
 	}
+	UI.QueueUpdateDraw(func() {
+		r.context.SetStarred(starred)
+		r.render()
+	})
+	return nil
+}
+
+// render draws the starred repositories into the list
+func (r *StarredWidget) render() {
+	starred := github.SortRepositories(r.context.State.Starred, r.order)
 	r.component.Clear()
+	r.visible = []*domain.Repository{}
 	if len(starred) == 0 {
 		r.component.AddItem("No repositories found", "", 0, nil)
 		return
@@ -76,34 +133,59 @@ func (r *StarredWidget) Refresh() (err error) {
 	if len(repos) > 20 {
 		repos = starred[:20]
 	}
+	r.visible = repos
 
 	for _, repo := range repos {
 		main, secondary, showSecondaryText, onSelect := repositoryEntry(repo)
 		r.component.AddItem(main, secondary, 0, onSelect).
 			ShowSecondaryText(showSecondaryText)
 	}
-	view.repos.addFavouriteIndicators()
-	return
+	r.addFavouriteIndicators()
+}
+
+// Reload fetches the starred repositories from github ignoring any cached copy then
+// redraws the list keeping the cursor on the same repository. This is blocking so
+// should be called from a goroutine.
+func (r *StarredWidget) Reload() error {
+	starred, err := github.SyncStarred(r.context)
+	if err != nil {
+		return err
+	}
+	UI.QueueUpdateDraw(func() {
+		current := r.getVisible(r.component.GetCurrentItem()).GetID()
+		r.rendering = true
+		defer func() { r.rendering = false }()
+		r.context.SetStarred(starred)
+		r.render()
+		if i := r.IndexOf(current); i >= 0 {
+			r.component.SetCurrentItem(i)
+		}
+	})
+	return nil
 }
 
 // addFavouriteIndicators loops through all repositories and if they have been previously
-// liked, adds a heart icon to the end of the name.
+// liked, adds a heart icon to the end of the name. The list is changed so this must be
+// called on the UI goroutine.
 func (r *StarredWidget) addFavouriteIndicators() {
 	for i := 0; i < r.component.GetItemCount(); i++ {
-		go r.addFavouriteIndicator(i)
+		r.addFavouriteIndicator(i)
 	}
 }
 
 func (r *StarredWidget) addFavouriteIndicator(i int) {
-	repo := r.context.GetStarred(i)
-	if isFavourite(r.context, repo) {
+	repo := r.getVisible(i)
+	if repo != nil && isFavourite(r.context, repo) {
 		main, secondary := r.component.GetItemText(i)
 		r.component.SetItemText(i, fmt.Sprintf("%s [hotpink]%s", main, heartIcon), secondary)
 	}
 }
 
 func (r *StarredWidget) OnChanged(index int, _, _ string, _ rune) {
-	repo := r.context.GetStarred(index)
+	if r.rendering {
+		return
+	}
+	repo := r.getVisible(index)
 	if repo == nil {
 		return
 	}
@@ -124,17 +206,17 @@ func onRepoSelect(ctx *app.Context, index int, _, _ string, _ rune) {
 	if !isFavourite(ctx, ctx.State.Selected) {
 		err := github.FavouriteSelectedRepo(ctx)
 		if err != nil {
-			openErrorModal(err)
+			showError(err)
 			return
 		}
-		go view.repos.addFavouriteIndicator(index)
+		view.repos.addFavouriteIndicator(index)
 	} else {
 		err := github.UnfavouriteSelected(ctx, index)
 		if err != nil {
-			openErrorModal(err)
+			showError(err)
 			return
 		}
-		go view.repos.removeFavouriteIndicator(index, ctx.State.Selected)
+		view.repos.removeFavouriteIndicator(index, ctx.State.Selected)
 	}
 }
 
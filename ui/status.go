@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"akinsho/gitgazer/api"
+	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/theme"
+
+	"github.com/rivo/tview"
+)
+
+const messagesPage = "messages"
+
+// toastDurations are how long a message is shown in the status bar for each level
+var toastDurations = map[app.LogLevel]time.Duration{
+	app.InfoLevel:  3 * time.Second,
+	app.WarnLevel:  5 * time.Second,
+	app.ErrorLevel: 8 * time.Second,
+}
+
+// StatusBar shows the most recent message below the layout until it is dismissed
+type StatusBar struct {
+	component *tview.TextView
+	// current identifies the message being shown so it is not cleared by the timer of an
+	// earlier message
+	current int
+}
+
+// show replaces the message in the status bar, it must be called from the UI goroutine
+func (s *StatusBar) show(level app.LogLevel, message string) {
+	s.current++
+	current := s.current
+	s.component.SetText(fmt.Sprintf(
+		"[%s::b]%s[-::-] %s",
+		theme.Tag(levelColor(level)),
+		strings.ToUpper(level.String()),
+		tview.Escape(message),
+	))
+	time.AfterFunc(toastDurations[level], func() {
+		UI.QueueUpdateDraw(func() {
+			if s.current == current {
+				s.component.Clear()
+			}
+		})
+	})
+}
+
+func statusBarWidget() *StatusBar {
+	status := tview.NewTextView().SetDynamicColors(true)
+	status.SetBorderPadding(0, 0, 1, 1)
+	return &StatusBar{component: status}
+}
+
+// notify logs the message and shows it in the status bar, it must be called from the
+// UI goroutine
+func notify(level app.LogLevel, message string) {
+	view.context.Logger.Log(level, message)
+	view.status.show(level, message)
+}
+
+// showError shows the error in the status bar without interrupting the user unless github
+// has rejected the access token in which case they are asked to log in again
+func showError(err error) {
+	if errors.Is(err, api.ErrUnauthorized) {
+		openLoginModal(view.context)
+		return
+	}
+	notify(app.ErrorLevel, err.Error())
+}
+
+// messagesText lists the recent log messages from newest to oldest, debug messages are
+// left out as they are only of interest in the log panel
+func messagesText(ctx *app.Context) string {
+	lines := []string{}
+	entries := ctx.Logger.Recent()
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Level < app.InfoLevel {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%s [%s]%-5s[-] %s",
+			entry.Time.Format("15:04:05"),
+			theme.Tag(levelColor(entry.Level)),
+			entry.Level,
+			tview.Escape(entry.Message),
+		))
+	}
+	if len(lines) == 0 {
+		return "No messages yet"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleMessages shows or hides the recent messages over the whole screen
+func toggleMessages(layout *Layout) {
+	toggleOverlay(layout, messagesPage, "Messages", func() string {
+		return messagesText(layout.context)
+	})
+}
@@ -5,6 +5,8 @@ import (
 
 	"akinsho/gitgazer/app"
 	"akinsho/gitgazer/common"
+	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/keymap"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -14,12 +16,17 @@ type panel struct {
 	title  string
 	widget Widget
 	id     string
+	name   domain.PanelName
 }
 
 type TabbedPanelWidget struct {
 	currentPanel int
 	component    *tview.Flex
 	entries      []panel
+	// status is shown after the tabs in the title e.g. how much of a list has loaded
+	status string
+	// layout is the layout the panel belongs to
+	layout *Layout
 }
 
 func (s *TabbedPanelWidget) SetCurrentIndex(index int) {
@@ -30,6 +37,11 @@ func (s *TabbedPanelWidget) CurrentItem() Widget {
 	return s.entries[s.currentPanel].widget
 }
 
+// CurrentName returns the name of the panel currently shown
+func (s *TabbedPanelWidget) CurrentName() domain.PanelName {
+	return s.entries[s.currentPanel].name
+}
+
 func (s *TabbedPanelWidget) CurrentTextView() TextWidget {
 	widget, ok := s.entries[s.currentPanel].widget.(TextWidget)
 	if !ok {
@@ -38,6 +50,21 @@ func (s *TabbedPanelWidget) CurrentTextView() TextWidget {
 	return widget
 }
 
+// UpdateTitle redraws the title of the panel e.g. after the current widget's filter has changed
+func (s *TabbedPanelWidget) UpdateTitle() {
+	title := getPanelTitle(s.entries, s.entries[s.currentPanel])
+	if s.status != "" {
+		title += " " + s.status
+	}
+	s.component.SetTitle(common.Pad(title, 1))
+}
+
+// SetStatus shows the text after the tabs in the title, an empty status removes it
+func (s *TabbedPanelWidget) SetStatus(status string) {
+	s.status = status
+	s.UpdateTitle()
+}
+
 func (s *TabbedPanelWidget) OnChange(
 	panels []panel,
 	pages *tview.Pages,
@@ -52,19 +79,34 @@ func (s *TabbedPanelWidget) OnChange(
 		s.SetCurrentIndex(index)
 		e := panels[index]
 		sidebar.SetTitle(common.Pad(getPanelTitle(panels, e), 1))
-		go handleRefresh(e, sidebar, panels)
+		s.layout.background(func() { handleRefresh(s.layout, e, sidebar, panels) })
 	}
 }
 
-func handleRefresh(selected panel, tabbedPanel *tview.Flex, panels []panel) {
+func handleRefresh(layout *Layout, selected panel, tabbedPanel *tview.Flex, panels []panel) {
 	err := selected.widget.Refresh()
 	UI.QueueUpdateDraw(func() {
+		// the layout has been replaced e.g. by switching profile
+		if layout != view {
+			return
+		}
 		if err != nil {
-			openErrorModal(err)
+			showError(err)
 		} else {
 			tabbedPanel.SetTitle(common.Pad(getPanelTitle(panels, selected), 1))
+			// the list is not focused behind another page e.g. when the dashboard was
+			// open whilst the layout was rebuilt
+			if page, _ := view.pages.GetFrontPage(); page != mainPage {
+				return
+			}
 			UI.SetFocus(selected.widget.Component())
-			view.ActiveList().SetSelected(0)
+			index := 0
+			if list, ok := view.ActiveList().(RepositoryListWidget); ok && view.reselect != "" {
+				if i := list.IndexOf(view.reselect); i >= 0 {
+					index = i
+				}
+			}
+			view.ActiveList().SetSelected(index)
 		}
 	})
 }
@@ -83,28 +125,44 @@ func sidebarInputHandler(
 	nextTab func(),
 	previousTab func(),
 ) *tcell.EventKey {
-	if event.Rune() == 'j' {
+	keys := view.keys()
+	switch {
+	case keys.Is(keymap.Down, event):
 		return tcell.NewEventKey(tcell.KeyDown, 'j', tcell.ModNone)
-	} else if event.Rune() == 'k' {
+	case keys.Is(keymap.Up, event):
 		return tcell.NewEventKey(tcell.KeyUp, 'k', tcell.ModNone)
-	} else if event.Rune() == 'l' {
+	case keys.Is(keymap.Right, event):
 		return tcell.NewEventKey(tcell.KeyRight, 'l', tcell.ModNone)
-	} else if event.Rune() == 'h' {
+	case keys.Is(keymap.Left, event):
 		return tcell.NewEventKey(tcell.KeyLeft, 'h', tcell.ModNone)
-	} else if event.Key() == tcell.KeyCtrlD {
+	case keys.Is(keymap.ReloadSelected, event):
+		reloadSelected(view.ActiveList().Context())
+		return nil
+	case keys.Is(keymap.ReloadList, event):
+		reloadList(view.sidebar, view.ActiveList())
+		return nil
+	case keys.Is(keymap.ScrollDown, event):
 		view.ActiveDetails().ScrollDown()
-	} else if event.Key() == tcell.KeyCtrlU {
+	case keys.Is(keymap.ScrollUp, event):
 		view.ActiveDetails().ScrollUp()
-	} else if event.Key() == tcell.KeyCtrlN {
+	case keys.Is(keymap.NextTab, event):
+		view.reselect = ""
 		nextTab()
 		return nil
-	} else if event.Key() == tcell.KeyCtrlP {
+	case keys.Is(keymap.PreviousTab, event):
+		view.reselect = ""
 		previousTab()
 		return nil
-	} else if event.Key() == tcell.KeyCtrlO {
+	case keys.Is(keymap.Sort, event):
+		if list, ok := view.ActiveList().(SortableWidget); ok {
+			order := list.CycleSort()
+			notify(app.InfoLevel, fmt.Sprintf("Sorted by %s", order))
+		}
+		return nil
+	case keys.Is(keymap.Open, event):
 		err := view.ActiveList().Open()
 		if err != nil {
-			openErrorModal(err)
+			showError(err)
 			return nil
 		}
 		return nil
@@ -138,15 +196,15 @@ func findNext(panels *tview.Pages, entries []panel, reverse bool) func() {
 	}
 }
 
-func panelWidget(_ *app.Context, focused int, entries []panel) *TabbedPanelWidget {
+func panelWidget(layout *Layout, focused int, entries []panel) *TabbedPanelWidget {
 	tabbedPanel := tview.NewFlex()
 	panels := tview.NewPages()
-	widget := &TabbedPanelWidget{component: tabbedPanel, entries: entries}
+	widget := &TabbedPanelWidget{component: tabbedPanel, entries: entries, layout: layout}
 	panels.SetChangedFunc(widget.OnChange(entries, panels, tabbedPanel))
 
 	tabbedPanel.
 		SetDirection(tview.FlexRow).
-		SetTitleColor(tcell.ColorBlue).
+		SetTitleColor(palette.Title).
 		SetTitleAlign(tview.AlignLeft)
 
 	previousTab := findNext(panels, entries, true)
@@ -178,6 +236,9 @@ func getPanelTitle(panels []panel, p panel) string {
 			count += fmt.Sprintf("(%d)", itemCount)
 		}
 		t := entry.title
+		if filtered, ok := entry.widget.(FilteredWidget); ok && filtered.Filter() != "" {
+			t += ":" + filtered.Filter()
+		}
 		if entry == p {
 			title += tview.Escape(fmt.Sprintf("[%s%s]", t, count))
 		} else {
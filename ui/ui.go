@@ -1,13 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"akinsho/gitgazer/api"
 	"akinsho/gitgazer/app"
 	"akinsho/gitgazer/common"
 	"akinsho/gitgazer/domain"
+	"akinsho/gitgazer/github"
+	"akinsho/gitgazer/keymap"
+	"akinsho/gitgazer/theme"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/gdamore/tcell/v2"
@@ -19,14 +25,19 @@ const (
 	rightPillIcon = "█"
 	repoIcon      = ""
 	headerChar    = "─"
+	promptPage    = "prompt"
+	mainPage      = "main"
 )
 
 var (
 	view *Layout
 	UI   *tview.Application
+	// palette is the theme of the current layout
+	palette *theme.Theme
 )
 
 type Layout struct {
+	context     *app.Context
 	pages       *tview.Pages
 	layout      *tview.Flex
 	main        *tview.Flex
@@ -37,7 +48,27 @@ type Layout struct {
 	prs         *PullRequestsWidget
 	sidebar     *TabbedPanelWidget
 	favourites  *FavouritesWidget
+	dashboard   *DashboardWidget
+	search      *SearchWidget
 	debug       *LogWidget
+	status      *StatusBar
+	// root holds the pages above the status bar so messages are shown on every page
+	root *tview.Flex
+	// reselect is the ID of the repository to select once the list has loaded, it is used
+	// to keep the selection when the layout is rebuilt
+	reselect string
+	// tasks tracks the goroutines using the layout's context so its database is
+	// only closed once they have finished
+	tasks sync.WaitGroup
+}
+
+// background runs f in a goroutine which is waited for before the layout's context is closed
+func (l *Layout) background(f func()) {
+	l.tasks.Add(1)
+	go func() {
+		defer l.tasks.Done()
+		f()
+	}()
 }
 
 func (l *Layout) ActiveList() ListWidget {
@@ -50,6 +81,11 @@ func (l *Layout) ActiveList() ListWidget {
 	}
 }
 
+// keys returns the keymap of the current context
+func (l *Layout) keys() *keymap.Keymap {
+	return l.context.Config.Keymap
+}
+
 func (l *Layout) ActiveDetails() TextWidget {
 	if view.issues.component.HasFocus() {
 		return view.issues
@@ -65,22 +101,107 @@ func (l *Layout) ActiveDetails() TextWidget {
 //--------------------------------------------------------------------------------------------------
 
 func appInputHandler(layout *Layout, event *tcell.EventKey) *tcell.EventKey {
-	elements := []tview.Primitive{
-		layout.ActiveList().Component(),
-		layout.description,
-		layout.ActiveDetails().Component(),
+	elements := []tview.Primitive{layout.ActiveList().Component()}
+	if layout.context.Config.UserConfig.Layout.Description.Show {
+		elements = append(elements, layout.description)
+	}
+	elements = append(elements, layout.ActiveDetails().Component())
+	// characters typed into an input should not trigger global bindings
+	if _, typing := UI.GetFocus().(*tview.InputField); typing && event.Key() == tcell.KeyRune {
+		return event
+	}
+	if layout.pages.HasPage(searchPage) && layout.search.handlesKey(event) {
+		return event
 	}
-	switch event.Key() {
-	case tcell.KeyCtrlQ:
+	keys := layout.keys()
+	switch {
+	case keys.Is(keymap.Quit, event):
 		UI.Stop()
-	case tcell.KeyTab:
+	case keys.Is(keymap.ToggleDashboard, event):
+		toggleDashboard(layout)
+		return nil
+	case keys.Is(keymap.Search, event):
+		toggleSearch(layout)
+		return nil
+	case keys.Is(keymap.SwitchProfile, event):
+		openProfilePicker(layout.context)
+		return nil
+	case keys.Is(keymap.CommandPalette, event):
+		openCommandPalette(layout.context)
+		return nil
+	case keys.Is(keymap.Help, event):
+		toggleHelp(layout)
+		return nil
+	case keys.Is(keymap.Messages, event):
+		toggleMessages(layout)
+		return nil
+	case keys.Is(keymap.Close, event):
+		if page, _ := layout.pages.GetFrontPage(); page == dashboardPage {
+			toggleDashboard(layout)
+			return nil
+		}
+	case keys.Is(keymap.NextSection, event):
 		cycleFocus(UI, elements, false)
-	case tcell.KeyBacktab:
+	case keys.Is(keymap.PreviousSection, event):
 		cycleFocus(UI, elements, true)
 	}
 	return event
 }
 
+// toggleDashboard switches between the main page and the dashboard page refreshing the
+// dashboard each time it is opened
+func toggleDashboard(layout *Layout) {
+	if page, _ := layout.pages.GetFrontPage(); page == dashboardPage {
+		layout.pages.SwitchToPage(mainPage)
+		UI.SetFocus(layout.ActiveList().Component())
+		return
+	}
+	layout.pages.SwitchToPage(dashboardPage)
+	UI.SetFocus(layout.dashboard.Component())
+	layout.background(func() {
+		if err := layout.dashboard.Refresh(); err != nil {
+			UI.QueueUpdateDraw(func() { showError(err) })
+		}
+	})
+}
+
+// toggleSearch shows or hides the repository search window above the current page
+func toggleSearch(layout *Layout) {
+	if layout.pages.HasPage(searchPage) {
+		layout.pages.RemovePage(searchPage)
+		UI.SetFocus(layout.ActiveList().Component())
+		if layout.ActiveList() == layout.favourites {
+			refreshWidget(layout.favourites)
+		}
+		return
+	}
+	layout.pages.AddPage(searchPage, floatingWindow(layout.search.Component(), 100, 30), true, true)
+	UI.SetFocus(layout.search.input)
+}
+
+// refreshWidget refreshes the widget in the background and reports any errors
+func refreshWidget(widget Widget) {
+	view.background(func() {
+		err := widget.Refresh()
+		UI.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+			}
+		})
+	})
+}
+
+// floatingWindow centres the primitive in a window of the given size above the current page
+func floatingWindow(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
 func cycleFocus(app *tview.Application, elements []tview.Primitive, reverse bool) {
 	for i, el := range elements {
 		if !el.HasFocus() {
@@ -102,15 +223,51 @@ func cycleFocus(app *tview.Application, elements []tview.Primitive, reverse bool
 	}
 }
 
+// openErrorModal shows the error in a modal which has to be dismissed, it should only be used
+// for errors the user cannot continue without seeing, otherwise use showError
 func openErrorModal(err error) {
+	if errors.Is(err, api.ErrUnauthorized) {
+		openLoginModal(view.context)
+		return
+	}
 	current := UI.GetFocus()
 	modal := getErrorModal(err, "Sorry! looks like something went wrong", func(_ int, _ string) {
-		view.pages.SwitchToPage("main")
+		view.pages.RemovePage("errors")
 		UI.SetFocus(current)
 	})
 	view.pages.AddPage("errors", modal, true, true)
 }
 
+// openPrompt shows a single line input above the current page, onSubmit is called with the
+// text entered when the user presses enter, pressing escape dismisses the prompt
+func openPrompt(title, label, text string, onSubmit func(string)) {
+	current := UI.GetFocus()
+	dismiss := func() {
+		view.pages.RemovePage(promptPage)
+		UI.SetFocus(current)
+	}
+	input := tview.NewInputField().
+		SetLabel(label).
+		SetText(text).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			value := input.GetText()
+			dismiss()
+			onSubmit(value)
+		case tcell.KeyEscape:
+			dismiss()
+		}
+	})
+	input.SetBorder(true).
+		SetTitle(common.Pad(title, 1)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+	view.pages.AddPage(promptPage, floatingWindow(input, 80, 3), true, true)
+	UI.SetFocus(input)
+}
+
 func getErrorModal(err error, title string, onDone func(int, string)) *tview.Modal {
 	lines := strings.Join([]string{title, "message: " + err.Error()}, "\n")
 	modal := tview.NewModal().
@@ -125,7 +282,7 @@ func createHeader(width int) string {
 }
 
 func convertToMarkdown(body string) string {
-	body, err := glamour.Render(body, "dark")
+	body, err := glamour.Render(body, palette.Markdown)
 	if err != nil {
 		return body
 	}
@@ -143,17 +300,25 @@ func repositoryEntry(repo domain.Repo) (string, string, bool, func()) {
 // has paused over a repository in the list for more than interval time
 func throttledListUpdate(duration time.Duration) func(*app.Context, *domain.Repository) {
 	var timer *time.Timer
+	// pending is the layout the timer was started for
+	var pending *Layout
 	return func(ctx *app.Context, repo *domain.Repository) {
 		if timer != nil {
-			timer.Stop()
+			if timer.Stop() {
+				pending.tasks.Done()
+			}
 			timer = nil
 		}
 		ctx.SetSelected(repo)
-		setRepoDescription(repo)
+		setRepoDescription(ctx, repo)
+		layout := view
+		layout.tasks.Add(1)
+		pending = layout
 		timer = time.AfterFunc(duration, func() {
-			err := view.ActiveDetails().Refresh()
+			defer layout.tasks.Done()
+			err := layout.ActiveDetails().Refresh()
 			if err != nil {
-				openErrorModal(err)
+				UI.QueueUpdateDraw(func() { showError(err) })
 			}
 		})
 	}
@@ -161,30 +326,75 @@ func throttledListUpdate(duration time.Duration) func(*app.Context, *domain.Repo
 
 var updateRepositoryList = throttledListUpdate(time.Millisecond * 200)
 
-func setRepoDescription(repo *domain.Repository) {
+func setRepoDescription(ctx *app.Context, repo *domain.Repository) {
 	view.description.SetTitle(common.Pad(repo.GetName(), 1)).
 		SetTitleAlign(tview.AlignLeft).
-		SetTitleColor(tcell.ColorBlue)
-	stars := fmt.Sprintf("[red]Stars[white]: 🌟%d", repo.GetStargazerCount())
-	issues := fmt.Sprintf("[red]Issues[white]: %d", repo.GetIssueCount())
-	url := fmt.Sprintf("[red]URL[white]: [blue::bu]%s", repo.URL)
-	prs := fmt.Sprintf("[red]Open PRs[white]: %d", repo.GetPullRequestCount())
-	text := strings.Join([]string{repo.GetDescription(), "", stars, issues, prs, url}, "\n")
-	view.description.SetText(text)
-}
-
-func helpWidget() *tview.TextView {
-	navAdvice := "Cycle through sections using [::b]TAB/SHIFT-TAB[::-]"
-	closeAdvice := "Quit using [::b]<C-Q>[::-] or [::b]<C-C>[::-]"
-	listNavAdvice := "Navigate through the list using [::b]j/k[::-]"
-	listNavScrollAdvice := "Scroll through the issues list using [::b]C-D/C-U[::-]"
-	helpText := strings.Join([]string{
-		navAdvice,
-		closeAdvice,
-		listNavAdvice,
-		listNavScrollAdvice,
-	}, " | ")
-	help := tview.NewTextView().SetText(helpText).SetDynamicColors(true)
+		SetTitleColor(palette.Title)
+	key := func(name string) string {
+		return fmt.Sprintf("[%s]%s[%s]:", theme.Tag(palette.Key), name, theme.Tag(palette.Text))
+	}
+	stars := fmt.Sprintf("%s 🌟%d", key("Stars"), repo.GetStargazerCount())
+	issues := fmt.Sprintf("%s %d", key("Issues"), repo.GetIssueCount())
+	url := fmt.Sprintf("%s [%s::bu]%s", key("URL"), theme.Tag(palette.Link), repo.URL)
+	prs := fmt.Sprintf("%s %d", key("Open PRs"), repo.GetPullRequestCount())
+	lines := []string{repo.GetDescription(), "", stars, issues, prs, url}
+	note, err := github.GetNote(ctx, repo)
+	if err != nil {
+		ctx.Logger.Warn(fmt.Sprintf("failed to read note for %s: %s", repo.GetName(), err))
+	} else if note != nil {
+		lines = append(lines, "", fmt.Sprintf(
+			"[%s]Note[%s]: %s",
+			theme.Tag(palette.Note),
+			theme.Tag(palette.Text),
+			tview.Escape(note.Body),
+		))
+	}
+	view.description.SetText(strings.Join(lines, "\n"))
+}
+
+// hint describes the keys bound to one or more actions in the help bar
+type hint struct {
+	format  string
+	actions []keymap.Action
+}
+
+var hints = []hint{
+	{"Show all keys using %s", []keymap.Action{keymap.Help}},
+	{"Show recent messages using %s", []keymap.Action{keymap.Messages}},
+	{"Run a command using %s", []keymap.Action{keymap.CommandPalette}},
+	{"Cycle through sections using %s", []keymap.Action{keymap.NextSection, keymap.PreviousSection}},
+	{"Quit using %s", []keymap.Action{keymap.Quit}},
+	{"Navigate through the list using %s", []keymap.Action{keymap.Down, keymap.Up}},
+	{"Scroll through the issues list using %s", []keymap.Action{keymap.ScrollDown, keymap.ScrollUp}},
+	{"Toggle your work using %s", []keymap.Action{keymap.ToggleDashboard}},
+	{"Search repositories using %s", []keymap.Action{keymap.Search}},
+	{"Switch profile using %s", []keymap.Action{keymap.SwitchProfile}},
+	{"Tag favourites using %s", []keymap.Action{keymap.EditTags}},
+	{"Switch group using %s", []keymap.Action{keymap.CycleGroup}},
+	{"Write a note using %s", []keymap.Action{keymap.EditNote}},
+	{"Refresh the repository using %s", []keymap.Action{keymap.ReloadSelected}},
+	{"Refresh the list using %s", []keymap.Action{keymap.ReloadList}},
+}
+
+// helpText describes the active keymap, actions without any keys are left out
+func helpText(keys *keymap.Keymap) string {
+	advice := []string{}
+	for _, h := range hints {
+		labels := []string{}
+		for _, action := range h.actions {
+			if label := keys.Label(action); label != "" {
+				labels = append(labels, label)
+			}
+		}
+		if len(labels) > 0 {
+			advice = append(advice, fmt.Sprintf(h.format, "[::b]"+strings.Join(labels, "/")+"[::-]"))
+		}
+	}
+	return strings.Join(advice, " | ")
+}
+
+func helpWidget(keys *keymap.Keymap) *tview.TextView {
+	help := tview.NewTextView().SetText(helpText(keys)).SetDynamicColors(true)
 	help.SetBorder(true)
 	return help
 }
@@ -199,69 +409,145 @@ func listWidget(opts ListOptions) *tview.List {
 	list.SetChangedFunc(opts.onChanged).
 		SetSelectedFunc(opts.onSelected).
 		SetHighlightFullLine(true).
-		SetSecondaryTextColor(tcell.ColorDarkGrey).
-		SetSelectedTextColor(tcell.ColorWhite).
-		SetSelectedBackgroundColor(tcell.ColorRebeccaPurple).
-		SetMainTextColor(tcell.ColorForestGreen).
+		SetSecondaryTextColor(palette.SecondaryText).
+		SetSelectedTextColor(palette.SelectedText).
+		SetSelectedBackgroundColor(palette.SelectedBackground).
+		SetMainTextColor(palette.ListText).
 		SetMainTextStyle(tcell.StyleDefault.Bold(true)).
 		SetBorderPadding(0, 0, 1, 1)
 	return list
 }
 
+// panelTitles are the titles of the tabs a panel is shown in
+var panelTitles = map[domain.PanelName]string{
+	domain.StarredRepositoriesPanel:   "Starred",
+	domain.FavouriteRepositoriesPanel: "Favourites",
+	domain.IssuesPanel:                "Issues",
+	domain.PullRequestPanel:           "PRs",
+}
+
+// orderedPanels returns the panels in the order they are listed in the config,
+// it also returns the index of the preferred panel or 0 if it is not shown
+func orderedPanels(
+	names []domain.PanelName,
+	widgets map[domain.PanelName]Widget,
+	preferred domain.PanelName,
+) ([]panel, int) {
+	panels := []panel{}
+	focused := 0
+	for i, name := range names {
+		if name == preferred {
+			focused = i
+		}
+		panels = append(panels, panel{id: name.String(), name: name, title: panelTitles[name], widget: widgets[name]})
+	}
+	return panels, focused
+}
+
+// repositoryPanelWidget creates the sidebar showing the current panel if there is one
 func repositoryPanelWidget(
-	context *app.Context,
+	layout *Layout,
 	favourites *FavouritesWidget,
 	starred *StarredWidget,
+	current domain.PanelName,
 ) *TabbedPanelWidget {
-	focused := 0
-	if !favourites.IsEmpty() {
-		focused = 1
+	preferred := current
+	if preferred == domain.UnknownPanel {
+		preferred = domain.StarredRepositoriesPanel
+		if !favourites.IsEmpty() {
+			preferred = domain.FavouriteRepositoriesPanel
+		}
 	}
-	sidebar := panelWidget(context, focused, []panel{
-		{id: domain.StarredRepositoriesPanel.String(), title: "Starred", widget: starred},
-		{id: domain.FavouriteRepositoriesPanel.String(), title: "Favourites", widget: favourites},
-	})
-	return sidebar
+	panels, focused := orderedPanels(
+		layout.context.Config.UserConfig.Layout.Sidebar.Panels,
+		map[domain.PanelName]Widget{
+			domain.StarredRepositoriesPanel:   starred,
+			domain.FavouriteRepositoriesPanel: favourites,
+		},
+		preferred,
+	)
+	return panelWidget(layout, focused, panels)
 }
 
+// repositoryDetailsPanelWidget creates the details pane showing the current panel if there is one
 func repositoryDetailsPanelWidget(
-	ctx *app.Context,
+	layout *Layout,
 	issues *IssuesWidget,
 	prs *PullRequestsWidget,
+	current domain.PanelName,
 ) *TabbedPanelWidget {
-	focused := 0
-	preferred := ctx.Config.UserConfig.Panels.Details.Preferred
-	if preferred == domain.PullRequestPanel {
-		focused = 1
+	config := layout.context.Config.UserConfig
+	preferred := current
+	if preferred == domain.UnknownPanel {
+		preferred = config.Panels.Details.Preferred
 	}
-	return panelWidget(nil, focused, []panel{
-		{id: domain.IssuesPanel.String(), title: "Issues", widget: issues},
-		{id: domain.PullRequestPanel.String(), title: "PRs", widget: prs},
-	})
+	panels, focused := orderedPanels(
+		config.Layout.Details.Panels,
+		map[domain.PanelName]Widget{
+			domain.IssuesPanel:      issues,
+			domain.PullRequestPanel: prs,
+		},
+		preferred,
+	)
+	return panelWidget(layout, focused, panels)
+}
+
+// setupTheme sets up the theme for the application from the theme in the app's config,
+// it must be called before any widgets are created as they read their colours when created
+func setupTheme(config *app.Config) {
+	palette = config.Theme
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    palette.Background,
+		ContrastBackgroundColor:     palette.ContrastBackground,
+		MoreContrastBackgroundColor: palette.MoreContrastBackground,
+		BorderColor:                 palette.Border,
+		TitleColor:                  palette.Title,
+		GraphicsColor:               palette.Border,
+		PrimaryTextColor:            palette.Text,
+		SecondaryTextColor:          palette.Prompt,
+		TertiaryTextColor:           palette.ListText,
+		InverseTextColor:            palette.Link,
+		ContrastSecondaryTextColor:  palette.SecondaryText,
+	}
+}
+
+// layoutState is the part of a layout which is kept when it is rebuilt for the same context
+// e.g. after the config is reloaded
+type layoutState struct {
+	// page is the page shown in front
+	page     string
+	sidebar  domain.PanelName
+	details  domain.PanelName
+	group    string
+	selected string
 }
 
-// TODO: pull colour values from config
-// setupTheme sets up the theme for the application which can be derived from the app's config
-func setupTheme(_ *app.Config) {
-	theme := tview.Theme{
-		PrimitiveBackgroundColor:    tview.Styles.PrimitiveBackgroundColor,
-		ContrastBackgroundColor:     tcell.ColorDimGray,
-		MoreContrastBackgroundColor: tcell.ColorRebeccaPurple,
-		BorderColor:                 tview.Styles.BorderColor,
-		TitleColor:                  tcell.ColorBlue,
-		GraphicsColor:               tview.Styles.GraphicsColor,
-		PrimaryTextColor:            tview.Styles.PrimaryTextColor,
-		SecondaryTextColor:          tview.Styles.SecondaryTextColor,
-		TertiaryTextColor:           tview.Styles.TertiaryTextColor,
-		InverseTextColor:            tview.Styles.InverseTextColor,
-		ContrastSecondaryTextColor:  tview.Styles.ContrastSecondaryTextColor,
+// state returns the current state of the layout so it can be restored by a new layout
+func (l *Layout) state() *layoutState {
+	page, _ := l.pages.GetFrontPage()
+	return &layoutState{
+		page:     page,
+		sidebar:  l.sidebar.CurrentName(),
+		details:  l.details.CurrentName(),
+		group:    l.favourites.group,
+		selected: l.context.State.Selected.GetID(),
 	}
-	tview.Styles = theme
 }
 
-func layoutWidget(ctx *app.Context) *Layout {
+// layoutWidget creates the layout for the context, the state of a previous layout is
+// restored if one is given
+func layoutWidget(ctx *app.Context, state *layoutState) *Layout {
+	if state == nil {
+		state = &layoutState{page: mainPage}
+	}
+	// the layout is created first so the panels can start their goroutines on it
+	l := &Layout{context: ctx, reselect: state.selected}
 	log := logWidget(ctx)
-	ctx.SetLogger(log)
+	if ctx.Config.UserConfig.Panels.Log.Enabled {
+		ctx.Logger.SetSink(log)
+	} else {
+		ctx.Logger.SetSink(nil)
+	}
 
 	pages := tview.NewPages()
 	description := tview.NewTextView()
@@ -270,59 +556,88 @@ func layoutWidget(ctx *app.Context) *Layout {
 	layout := tview.NewFlex()
 
 	favourites := favouritesWidget(ctx)
+	favourites.group = state.group
 	repos := starredWidget(ctx)
 	issues := issuesWidget(ctx)
 	prs := pullRequestsWidget(ctx)
+	dashboard := dashboardWidget(ctx)
+	search := searchWidget(ctx)
 
-	sidebar := repositoryPanelWidget(ctx, favourites, repos)
-	details := repositoryDetailsPanelWidget(ctx, issues, prs)
+	sidebar := repositoryPanelWidget(l, favourites, repos, state.sidebar)
+	details := repositoryDetailsPanelWidget(l, issues, prs, state.details)
 
 	description.SetDynamicColors(true).SetBorder(true)
 
+	options := ctx.Config.UserConfig.Layout
 	main.SetDirection(tview.FlexRow)
-	main.
-		AddItem(description, 0, 1, false).
-		AddItem(details.component, 0, 3, false)
+	if options.Description.Show {
+		main.AddItem(description, 0, options.Description.Size, false)
+	}
+	main.AddItem(details.component, 0, options.Details.Size, false)
 
 	isDebugging := ctx.Config.UserConfig.Panels.Log.Enabled
 	if isDebugging {
-		main.AddItem(log.component, 0, 1, false)
+		main.AddItem(log.component, 0, options.Log.Size, false)
 	}
 
 	layout.
-		AddItem(sidebar.component, 0, 1, false).
-		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
-			AddItem(main, 0, 3, false), 0, 3, false)
-
-	frame.AddItem(layout, 0, 1, false).AddItem(helpWidget(), 3, 0, false)
-
-	pages.AddPage("main", frame, true, true)
-
-	return &Layout{
-		pages:       pages,
-		main:        main,
-		description: description,
-		layout:      layout,
-		repos:       repos,
-		issues:      issues,
-		sidebar:     sidebar,
-		details:     details,
-		prs:         prs,
-		debug:       log,
-		favourites:  favourites,
+		AddItem(sidebar.component, 0, options.Sidebar.Size, false).
+		AddItem(main, 0, options.Main.Size, false)
+
+	status := statusBarWidget()
+	frame.AddItem(layout, 0, 1, false)
+	if options.Help {
+		frame.AddItem(helpWidget(ctx.Config.Keymap), 3, 0, false)
 	}
+
+	pages.AddPage(mainPage, frame, true, true)
+	pages.AddPage(dashboardPage, dashboard.component, true, false)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(pages, 0, 1, true).
+		AddItem(status.component, 1, 0, false)
+
+	l.root = root
+	l.pages = pages
+	l.main = main
+	l.description = description
+	l.layout = layout
+	l.repos = repos
+	l.issues = issues
+	l.sidebar = sidebar
+	l.details = details
+	l.prs = prs
+	l.debug = log
+	l.status = status
+	l.favourites = favourites
+	l.dashboard = dashboard
+	l.search = search
+	return l
 }
 
 func Setup(context *app.Context) error {
-	setupTheme(context.Config)
 	UI = tview.NewApplication()
-	view = layoutWidget(context)
+	setupLayout(context, nil)
+	defer func() { stopAutoRefresh() }()
+	stopWatchingConfig := watchConfig(context)
+	defer stopWatchingConfig()
+	if err := UI.EnableMouse(true).Run(); err != nil {
+		return err
+	}
+	return nil
+}
 
+// setupLayout creates the layout for the context replacing the current one if any, the
+// state of the previous layout is restored if one is given
+func setupLayout(context *app.Context, state *layoutState) {
+	setupTheme(context.Config)
+	view = layoutWidget(context, state)
 	UI.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		return appInputHandler(view, event)
 	})
-	if err := UI.SetRoot(view.pages, true).EnableMouse(true).Run(); err != nil {
-		return err
+	stopAutoRefresh = startAutoRefresh(view, context.Config.UserConfig.Refresh.Interval)
+	UI.SetRoot(view.root, true)
+	if state != nil && state.page == dashboardPage {
+		toggleDashboard(view)
 	}
-	return nil
 }
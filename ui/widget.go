@@ -2,6 +2,7 @@ package ui
 
 import (
 	"akinsho/gitgazer/app"
+	"akinsho/gitgazer/github"
 
 	"github.com/rivo/tview"
 )
@@ -19,8 +20,35 @@ type ListWidget interface {
 	SetSelected(int)
 }
 
+// RepositoryListWidget is a list of repositories which can find where a repository is shown
+type RepositoryListWidget interface {
+	ListWidget
+	// IndexOf returns the index of the repository with the ID or -1 if it is not shown
+	IndexOf(id string) int
+}
+
+// SortableWidget is a list which can be shown in different orders
+type SortableWidget interface {
+	ListWidget
+	// CycleSort shows the list in the next order and returns it
+	CycleSort() github.SortOrder
+}
+
+// ReloadableWidget is a list whose contents can be re-fetched from github on demand
+type ReloadableWidget interface {
+	ListWidget
+	Reload() error
+}
+
 type TextWidget interface {
 	Widget
 	ScrollUp()
 	ScrollDown()
 }
+
+// FilteredWidget is a widget whose contents can be narrowed down, the filter is shown
+// alongside the widget's title
+type FilteredWidget interface {
+	Widget
+	Filter() string
+}